@@ -0,0 +1,81 @@
+package atomicfile
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Write writes a file atomically. The content is written by fn
+// to a temporary file in the same directory which is then synced
+// and renamed to path, so readers (and crashes) either see
+// the old or the new file but never a partially written one.
+func Write(path string, perm os.FileMode, fn func(w io.Writer) error) error {
+	dir := filepath.Dir(path)
+
+	f, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("could not create temp file: %w", err)
+	}
+
+	tmp := f.Name()
+	committed := false
+	defer func() {
+		if !committed {
+			f.Close()
+			os.Remove(tmp)
+		}
+	}()
+
+	err = fn(f)
+	if err != nil {
+		return err
+	}
+
+	err = f.Chmod(perm)
+	if err != nil {
+		return fmt.Errorf("could not chmod temp file: %w", err)
+	}
+
+	err = f.Sync()
+	if err != nil {
+		return fmt.Errorf("could not sync temp file: %w", err)
+	}
+
+	err = f.Close()
+	if err != nil {
+		return fmt.Errorf("could not close temp file: %w", err)
+	}
+
+	err = os.Rename(tmp, path)
+	if err != nil {
+		return fmt.Errorf("could not rename temp file: %w", err)
+	}
+
+	committed = true
+
+	syncDir(dir)
+
+	return nil
+}
+
+// WriteBytes is Write for content that is already in memory.
+func WriteBytes(path string, perm os.FileMode, b []byte) error {
+	return Write(path, perm, func(w io.Writer) error {
+		_, err := w.Write(b)
+		return err
+	})
+}
+
+// syncDir makes the rename durable.
+// Not every platform supports syncing directories, so errors are ignored.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	defer d.Close()
+
+	_ = d.Sync()
+}
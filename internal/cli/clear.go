@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/devusSs/minls/internal/log"
+	"github.com/devusSs/minls/internal/paths"
 	"github.com/devusSs/minls/internal/storage"
 )
 
@@ -93,24 +94,35 @@ func handleClearOption(opt clearOption) error {
 	case clearOptionInvalid:
 		return errors.New("invalid clear option passed")
 	case clearOptionAll:
-		err := storage.RemoveStorageDir()
+		err := clearData()
 		if err != nil {
-			return fmt.Errorf("could not remove storage dir: %w", err)
+			return err
 		}
 
-		err = log.RemoveLogsDir()
+		err = log.RemoveLogs()
 		if err != nil {
-			return fmt.Errorf("could not remove logs dir: %w", err)
+			return fmt.Errorf("could not remove logs: %w", err)
 		}
 
 		return nil
 	case clearOptionData:
-		return storage.RemoveStorageDir()
+		return clearData()
 	case clearOptionLogs:
-		return log.RemoveLogsDir()
+		return log.RemoveLogs()
 	case clearOptionDownloads:
 		return errors.New("downloads not implemented yet")
 	default:
 		return fmt.Errorf("unknown clear option passed: %v", opt)
 	}
 }
+
+// clearData removes the files minls owns in the data dir,
+// the dir may be shared with other files (e.g. via --data-dir).
+func clearData() error {
+	err := storage.RemoveData()
+	if err != nil {
+		return fmt.Errorf("could not remove data: %w", err)
+	}
+
+	return paths.RemoveLegacyMarker()
+}
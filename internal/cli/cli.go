@@ -3,21 +3,57 @@ package cli
 import (
 	"fmt"
 	"log/slog"
+	"os"
+	"strings"
 
 	"github.com/devusSs/minls/internal/clip"
+	"github.com/devusSs/minls/internal/config"
 	"github.com/devusSs/minls/internal/log"
+	"github.com/devusSs/minls/internal/paths"
 	"github.com/devusSs/minls/internal/storage"
 )
 
 func initialize() error {
-	err := log.Init()
+	return initializeWith(storage.Init)
+}
+
+// initializeWith initializes like initialize but uses storageInit
+// to set up the storage (e.g. storage.InitNoMigrate).
+func initializeWith(storageInit func() error) error {
+	// before log.Init since the logs dir may be moved
+	moved, err := paths.MigrateLegacy()
+	if err != nil {
+		return fmt.Errorf("could not migrate legacy files: %w", err)
+	}
+
+	// before log.Init since the log level may be configured
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("could not load config: %w", err)
+	}
+
+	err = cfg.Apply()
+	if err != nil {
+		return fmt.Errorf("could not apply config: %w", err)
+	}
+
+	activeProfile = cfg.Profile
+	activeConfig = cfg
+
+	addLogSecrets(cfg)
+
+	err = log.Init()
 	if err != nil {
 		return fmt.Errorf("could not init log: %w", err)
 	}
 
-	log.Debug("cli - initialize", slog.String("action", "log_init"))
+	log.Debug("cli - initialize", slog.String("action", "log_init"), slog.String("profile", activeProfile))
+
+	for _, m := range moved {
+		log.Info("cli - initialize", slog.String("action", "migrate_legacy"), slog.String("info", m))
+	}
 
-	err = storage.Init()
+	err = storageInit()
 	if err != nil {
 		return fmt.Errorf("could not init storage: %w", err)
 	}
@@ -33,3 +69,61 @@ func initialize() error {
 
 	return nil
 }
+
+// globalFlags are accepted by every command and set the environment
+// variable they override, so flags take precedence over the environment.
+var globalFlags = map[string]string{
+	"--data-dir":   paths.EnvDataDir,
+	"--log-dir":    paths.EnvLogDir,
+	"--config-dir": paths.EnvConfigDir,
+	"--profile":    config.EnvProfile,
+}
+
+// setFlag overrides a config key, e.g. --set log.level=debug.
+const setFlag = "--set"
+
+// ParseGlobalFlags applies and removes the global flags from args,
+// they may appear anywhere as --flag value or --flag=value.
+func ParseGlobalFlags(args []string) ([]string, error) {
+	rest := make([]string, 0, len(args))
+
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+
+		envKey, ok := globalFlags[name]
+		if !ok && name != setFlag {
+			rest = append(rest, args[i])
+			continue
+		}
+
+		if !hasValue {
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("flag %s needs a value", name)
+			}
+
+			i++
+			value = args[i]
+		}
+
+		if name == setFlag {
+			key, v, found := strings.Cut(value, "=")
+			if !found {
+				return nil, fmt.Errorf("flag %s needs key=value, got %q", setFlag, value)
+			}
+
+			err := config.SetFlag(key, v)
+			if err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		err := os.Setenv(envKey, value)
+		if err != nil {
+			return nil, fmt.Errorf("could not set %s: %w", envKey, err)
+		}
+	}
+
+	return rest, nil
+}
@@ -0,0 +1,254 @@
+package cli
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/devusSs/minls/internal/config"
+	"github.com/devusSs/minls/internal/log"
+	"github.com/devusSs/minls/internal/secrets"
+)
+
+// Config handles the config subcommands.
+func Config() error {
+	if len(os.Args) < configMinArgs {
+		return errors.New("missing config subcommand (options: show / profiles / set-secret)")
+	}
+
+	sub := os.Args[2]
+	args := os.Args[3:]
+
+	switch sub {
+	case "show":
+		return configShow(args)
+	case "profiles":
+		return configProfiles(args)
+	case "set-secret":
+		return configSetSecret(args)
+	default:
+		return fmt.Errorf("unknown config subcommand: %s", sub)
+	}
+}
+
+const configMinArgs = 3
+
+func configShow(args []string) error {
+	err := initialize()
+	if err != nil {
+		return fmt.Errorf("could not initialize: %w", err)
+	}
+
+	log.Debug("cli - configShow", slog.String("action", "initialized"))
+
+	fs := flag.NewFlagSet("config show", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print the config as JSON")
+	all := fs.Bool("all", false, "also show keys which are not set")
+
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 0 {
+		return fmt.Errorf("unexpected args: %v", positional)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("could not load config: %w", err)
+	}
+
+	values := make([]*config.Value, 0, len(cfg.Values))
+	for _, v := range cfg.Values {
+		if v.IsSet() || *all {
+			masked := *v
+			masked.Value = v.Masked()
+			values = append(values, &masked)
+		}
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(values)
+	}
+
+	fmt.Printf("Profile: %s\n\n", cfg.Profile)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Key\tValue\tSource")
+
+	for _, v := range values {
+		source := v.Source
+		if v.Path != "" {
+			source += " (" + v.Path + ")"
+		}
+
+		if v.Profile != "" {
+			source += " [profile " + v.Profile + "]"
+		}
+
+		if source == "" {
+			source = "unset"
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\n", v.Name, v.Value, source)
+	}
+
+	return w.Flush()
+}
+
+// configProfiles lists the configured profiles and marks the active one.
+func configProfiles(args []string) error {
+	err := initialize()
+	if err != nil {
+		return fmt.Errorf("could not initialize: %w", err)
+	}
+
+	log.Debug("cli - configProfiles", slog.String("action", "initialized"))
+
+	if len(args) != 0 {
+		return fmt.Errorf("unexpected args: %v", args)
+	}
+
+	profiles, err := config.Profiles()
+	if err != nil {
+		return fmt.Errorf("could not read profiles: %w", err)
+	}
+
+	for _, p := range profiles {
+		marker := " "
+		if p == activeProfile {
+			marker = "*"
+		}
+
+		fmt.Printf("%s %s\n", marker, p)
+	}
+
+	return nil
+}
+
+// configSetSecret writes a secret key of the active profile to the secret store,
+// the value is prompted for without echo or read from stdin.
+func configSetSecret(args []string) error {
+	err := initialize()
+	if err != nil {
+		return fmt.Errorf("could not initialize: %w", err)
+	}
+
+	log.Debug("cli - configSetSecret", slog.String("action", "initialized"))
+
+	fs := flag.NewFlagSet("config set-secret", flag.ContinueOnError)
+	remove := fs.Bool("delete", false, "delete the secret instead of setting it")
+
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 1 {
+		return errors.New("usage: minls config set-secret <key> [--delete]")
+	}
+
+	k := config.FindKey(positional[0])
+	if k == nil {
+		return fmt.Errorf("unknown config key %q", positional[0])
+	}
+
+	if !k.Secret {
+		return fmt.Errorf("%s is not a secret, set it in %s instead", k.Name, config.UserFileName)
+	}
+
+	store, err := config.SecretStore()
+	if err != nil {
+		return err
+	}
+
+	if *remove {
+		err = store.Delete(activeProfile, k.Env)
+		if err != nil {
+			return fmt.Errorf("could not delete %s: %w", k.Name, err)
+		}
+
+		fmt.Printf("Deleted %s of profile %s from %s.\n", k.Name, activeProfile, store.Location())
+
+		return nil
+	}
+
+	value, err := readSecretValue(k)
+	if err != nil {
+		return err
+	}
+
+	err = store.Set(activeProfile, k.Env, value)
+	if err != nil {
+		return fmt.Errorf("could not store %s: %w", k.Name, err)
+	}
+
+	log.Debug("cli - configSetSecret", slog.String("action", "stored"), slog.String("key", k.Name))
+
+	fmt.Printf("Stored %s of profile %s in %s.\n", k.Name, activeProfile, store.Location())
+
+	warnPlaintextSecret(k)
+
+	return nil
+}
+
+// readSecretValue prompts for the value or reads it from piped stdin.
+func readSecretValue(k *config.Key) (string, error) {
+	if secrets.IsTerminal() {
+		value, err := secrets.ReadHidden(fmt.Sprintf("Value for %s: ", k.Name))
+		if err != nil {
+			return "", err
+		}
+
+		if value == "" {
+			return "", errors.New("empty value")
+		}
+
+		return value, nil
+	}
+
+	raw, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", fmt.Errorf("could not read stdin: %w", err)
+	}
+
+	value := strings.TrimRight(string(raw), "\r\n")
+	if value == "" {
+		return "", errors.New("empty value on stdin")
+	}
+
+	return value, nil
+}
+
+// warnPlaintextSecret points out plaintext copies of the secret,
+// which take precedence over the secret store.
+func warnPlaintextSecret(k *config.Key) {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Warn("cli - warnPlaintextSecret", slog.String("warn", "could not load config"), slog.Any("err", err))
+		return
+	}
+
+	for _, v := range cfg.Values {
+		if v.Key != k || v.Source == "" || v.Source == config.SourceSecrets {
+			continue
+		}
+
+		fmt.Fprintf(
+			os.Stderr,
+			"WARNING: %s is set in plaintext by %s which takes precedence, remove it there\n",
+			k.Name,
+			cmp.Or(v.Path, v.Source),
+		)
+	}
+}
@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/devusSs/minls/internal/config"
+	"github.com/devusSs/minls/internal/minio"
+	"github.com/devusSs/minls/internal/shortener"
+	"github.com/devusSs/minls/internal/storage"
+	"github.com/devusSs/minls/internal/yourls"
+)
+
+// config writes the defaults out instead of importing the packages,
+// so they must be kept in sync by hand.
+func TestKeyDefaults(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{key: "MINIO_CREDENTIALS_SOURCE", want: minio.CredentialsStatic},
+		{key: "MINIO_BUCKET_LOOKUP", want: minio.BucketLookupAuto},
+		{key: "MINIO_BUCKET_PUBLIC", want: minio.DefaultBucketPublic},
+		{key: "MINIO_BUCKET_PRIVATE", want: minio.DefaultBucketPrivate},
+		{key: "MINIO_LINK_EXPIRY", want: minio.DefaultLinkExpiry.String()},
+		{key: "SHORTENER", want: shortener.NameYOURLS},
+		{key: "SHORTENER_KEYWORD_STYLE", want: shortener.KeywordStyleUUID},
+		{key: "SHORTENER_KEYWORD_LENGTH", want: strconv.Itoa(shortener.DefaultKeywordLength)},
+		{key: "YOURLS_AUTH_MODE", want: yourls.AuthModeSignature},
+		{key: "YOURLS_SIGNATURE_HASH", want: yourls.DefaultSignatureHash},
+		{key: "YOURLS_TIMEOUT", want: yourls.DefaultTimeout.String()},
+		{key: "YOURLS_RETRIES", want: strconv.Itoa(yourls.DefaultMaxRetries)},
+		{key: "DATA_RETENTION", want: storage.DefaultRetention.String()},
+	}
+
+	for _, tt := range tests {
+		if got := config.FindKey(tt.key).Default; got != tt.want {
+			t.Errorf("default of %s = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
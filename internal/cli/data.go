@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/devusSs/minls/internal/log"
+	"github.com/devusSs/minls/internal/storage"
+)
+
+// Data handles the data subcommands.
+func Data() error {
+	if len(os.Args) < dataMinArgs {
+		return errors.New("missing data subcommand (options: migrate / export / import)")
+	}
+
+	sub := os.Args[2]
+	args := os.Args[3:]
+
+	switch sub {
+	case "migrate":
+		return dataMigrate(args)
+	case "export":
+		return dataExport(args)
+	case "import":
+		return dataImport(args)
+	default:
+		return fmt.Errorf("unknown data subcommand: %s", sub)
+	}
+}
+
+const dataMinArgs = 3
+
+func dataMigrate(args []string) error {
+	// we do not want to migrate automatically before a dry run
+	err := initializeWith(storage.InitNoMigrate)
+	if err != nil {
+		return fmt.Errorf("could not initialize: %w", err)
+	}
+
+	log.Debug("cli - dataMigrate", slog.String("action", "initialized"))
+
+	fs := flag.NewFlagSet("data migrate", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "only preview the migration")
+	asJSON := fs.Bool("json", false, "print the migration report as JSON")
+
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 0 {
+		return fmt.Errorf("unexpected args: %v", positional)
+	}
+
+	report, err := storage.Migrate(*dryRun)
+	if err != nil {
+		return fmt.Errorf("could not migrate: %w", err)
+	}
+
+	log.Debug("cli - dataMigrate", slog.String("action", "migrated"), slog.Any("report", report))
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+
+	printMigrationReport(report)
+
+	return nil
+}
+
+func printMigrationReport(report *storage.MigrationReport) {
+	if len(report.Steps) == 0 {
+		fmt.Printf("History is up to date (version %d).\n", report.ToVersion)
+		return
+	}
+
+	if report.DryRun {
+		fmt.Printf(
+			"Dry run: would migrate history from version %d to %d.\n",
+			report.FromVersion,
+			report.ToVersion,
+		)
+	} else {
+		fmt.Printf("Migrated history from version %d to %d.\n", report.FromVersion, report.ToVersion)
+		fmt.Printf("Backup written to %s\n", report.BackupPath)
+	}
+
+	for _, step := range report.Steps {
+		fmt.Println()
+		fmt.Printf("v%d -> v%d: %s\n", step.FromVersion, step.ToVersion, step.Description)
+
+		if len(step.Changes) == 0 {
+			fmt.Println("	no entries changed")
+		}
+
+		for _, change := range step.Changes {
+			fmt.Println("	" + change)
+		}
+	}
+}
@@ -0,0 +1,400 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/devusSs/minls/internal/atomicfile"
+	"github.com/devusSs/minls/internal/log"
+	"github.com/devusSs/minls/internal/storage"
+)
+
+const (
+	exportFormatJSON   = "json"
+	exportFormatCSV    = "csv"
+	exportFormatNDJSON = "ndjson"
+)
+
+// stdioPath reads from stdin or writes to stdout instead of a file.
+const stdioPath = "-"
+
+func dataExport(args []string) error {
+	err := initialize()
+	if err != nil {
+		return fmt.Errorf("could not initialize: %w", err)
+	}
+
+	log.Debug("cli - dataExport", slog.String("action", "initialized"))
+
+	fs := flag.NewFlagSet("data export", flag.ContinueOnError)
+	format := fs.String("format", "", "json / csv / ndjson, defaults to the file extension")
+	fieldList := fs.String("fields", "", "comma separated fields to export ("+strings.Join(dataEntryFields(), ", ")+")")
+
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 1 {
+		return errors.New("missing export file (use - for stdout)")
+	}
+
+	path := positional[0]
+
+	if *format == "" {
+		*format = formatFromPath(path)
+	}
+
+	fields, err := parseExportFields(*fieldList)
+	if err != nil {
+		return err
+	}
+
+	data, err := storage.ReadData()
+	if err != nil {
+		return fmt.Errorf("could not read data from storage: %w", err)
+	}
+
+	slices.SortFunc(data.Entries, func(a *storage.DataEntry, b *storage.DataEntry) int { return a.ID - b.ID })
+
+	rows, err := exportRows(data.Entries, fields)
+	if err != nil {
+		return err
+	}
+
+	write := func(w io.Writer) error {
+		switch *format {
+		case exportFormatJSON:
+			return writeExportJSON(w, data.Version, rows)
+		case exportFormatNDJSON:
+			return writeExportNDJSON(w, rows)
+		case exportFormatCSV:
+			return writeExportCSV(w, fields, rows)
+		default:
+			return fmt.Errorf("unknown export format %q (options: json / csv / ndjson)", *format)
+		}
+	}
+
+	if path == stdioPath {
+		return write(os.Stdout)
+	}
+
+	err = atomicfile.Write(path, 0600, write)
+	if err != nil {
+		return fmt.Errorf("could not write %s: %w", path, err)
+	}
+
+	log.Debug("cli - dataExport", slog.String("path", path), slog.Int("entries", len(rows)))
+
+	fmt.Printf("Exported %d entries to %s\n", len(rows), path)
+
+	return nil
+}
+
+// formatFromPath guesses the format by the file extension, stdout defaults to json.
+func formatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return exportFormatCSV
+	case ".ndjson", ".jsonl":
+		return exportFormatNDJSON
+	default:
+		return exportFormatJSON
+	}
+}
+
+// dataEntryFields returns the JSON names of all DataEntry fields.
+func dataEntryFields() []string {
+	t := reflect.TypeFor[storage.DataEntry]()
+
+	fields := make([]string, 0, t.NumField())
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		fields = append(fields, name)
+	}
+
+	return fields
+}
+
+func parseExportFields(s string) ([]string, error) {
+	all := dataEntryFields()
+	if s == "" {
+		return all, nil
+	}
+
+	fields := make([]string, 0)
+	for name := range strings.SplitSeq(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		if !slices.Contains(all, name) {
+			return nil, fmt.Errorf("unknown field %q (options: %s)", name, strings.Join(all, ", "))
+		}
+
+		fields = append(fields, name)
+	}
+
+	if len(fields) == 0 {
+		return nil, errors.New("no fields selected")
+	}
+
+	return fields, nil
+}
+
+// exportRows converts the entries to JSON objects with only the selected fields.
+func exportRows(entries []*storage.DataEntry, fields []string) ([]map[string]any, error) {
+	rows := make([]map[string]any, 0, len(entries))
+
+	for _, entry := range entries {
+		b, err := json.Marshal(entry)
+		if err != nil {
+			return nil, fmt.Errorf("could not marshal entry %d: %w", entry.ID, err)
+		}
+
+		full := make(map[string]any)
+		err = json.Unmarshal(b, &full)
+		if err != nil {
+			return nil, fmt.Errorf("could not unmarshal entry %d: %w", entry.ID, err)
+		}
+
+		row := make(map[string]any, len(fields))
+		for _, f := range fields {
+			if v, ok := full[f]; ok {
+				row[f] = v
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func writeExportJSON(w io.Writer, version int, rows []map[string]any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		Version int              `json:"version"`
+		Entries []map[string]any `json:"entries"`
+	}{version, rows})
+}
+
+func writeExportNDJSON(w io.Writer, rows []map[string]any) error {
+	enc := json.NewEncoder(w)
+	for _, row := range rows {
+		err := enc.Encode(row)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// csvTagSeparator joins list values like tags in a single CSV cell.
+const csvTagSeparator = ";"
+
+func writeExportCSV(w io.Writer, fields []string, rows []map[string]any) error {
+	cw := csv.NewWriter(w)
+
+	err := cw.Write(fields)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		cells := make([]string, 0, len(fields))
+		for _, f := range fields {
+			cells = append(cells, csvCell(row[f]))
+		}
+
+		err = cw.Write(cells)
+		if err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func csvCell(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case []any:
+		parts := make([]string, 0, len(v))
+		for _, p := range v {
+			parts = append(parts, csvCell(p))
+		}
+
+		return strings.Join(parts, csvTagSeparator)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func dataImport(args []string) error {
+	err := initialize()
+	if err != nil {
+		return fmt.Errorf("could not initialize: %w", err)
+	}
+
+	log.Debug("cli - dataImport", slog.String("action", "initialized"))
+
+	fs := flag.NewFlagSet("data import", flag.ContinueOnError)
+	format := fs.String("format", "", "json / ndjson, defaults to the file extension")
+	fromMinio := fs.Bool("from-minio", false, "rebuild the history from the objects in the minio buckets")
+	dryRun := fs.Bool("dry-run", false, "only print what would be imported")
+	asJSON := fs.Bool("json", false, "print the import report as JSON")
+
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if *fromMinio {
+		if len(positional) != 0 {
+			return errors.New("--from-minio does not take a file")
+		}
+
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer cancel()
+
+		return reconcile(ctx, *dryRun)
+	}
+
+	if len(positional) != 1 {
+		return errors.New("missing import file (use - for stdin) or --from-minio")
+	}
+
+	path := positional[0]
+
+	if *format == "" {
+		*format = formatFromPath(path)
+	}
+
+	var b []byte
+	if path == stdioPath {
+		b, err = io.ReadAll(os.Stdin)
+	} else {
+		b, err = os.ReadFile(path)
+	}
+
+	if err != nil {
+		return fmt.Errorf("could not read %s: %w", path, err)
+	}
+
+	data, err := parseImport(b, *format)
+	if err != nil {
+		return fmt.Errorf("could not parse %s: %w", path, err)
+	}
+
+	report, err := storage.Import(data, *dryRun)
+	if err != nil {
+		return fmt.Errorf("could not import: %w", err)
+	}
+
+	log.Debug("cli - dataImport", slog.String("path", path), slog.Any("report", report))
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+
+	printImportReport(report)
+
+	return nil
+}
+
+// parseImport reads a json export (versioned envelope or plain list of entries)
+// or an ndjson export. Exports without a version are kept at version 0,
+// so storage.Import treats them as legacy data and runs all migrations.
+func parseImport(b []byte, format string) (*storage.Data, error) {
+	data := &storage.Data{}
+
+	switch format {
+	case exportFormatJSON:
+		if bytes.HasPrefix(bytes.TrimSpace(b), []byte("[")) {
+			return data, json.Unmarshal(b, &data.Entries)
+		}
+
+		err := json.Unmarshal(b, data)
+		if err != nil {
+			return nil, err
+		}
+
+		return data, nil
+	case exportFormatNDJSON:
+		sc := bufio.NewScanner(bytes.NewReader(b))
+		sc.Buffer(nil, 1<<20)
+
+		for line := 1; sc.Scan(); line++ {
+			if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+				continue
+			}
+
+			entry := &storage.DataEntry{}
+			err := json.Unmarshal(sc.Bytes(), entry)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+
+			data.Entries = append(data.Entries, entry)
+		}
+
+		return data, sc.Err()
+	case exportFormatCSV:
+		return nil, errors.New("csv exports cannot be imported, export as json or ndjson instead")
+	default:
+		return nil, fmt.Errorf("unknown import format %q (options: json / ndjson)", format)
+	}
+}
+
+func printImportReport(report *storage.ImportReport) {
+	if report.DryRun {
+		fmt.Print("Dry run: would import ")
+	} else {
+		fmt.Print("Imported ")
+	}
+
+	fmt.Printf(
+		"%d new entries, merged %d, skipped %d already known.\n",
+		report.Added,
+		report.Merged,
+		report.Skipped,
+	)
+
+	for _, oldID := range slices.Sorted(maps.Keys(report.Renumbered)) {
+		fmt.Printf("	id %d was taken, imported as %d\n", oldID, report.Renumbered[oldID])
+	}
+
+	if report.BackupPath != "" {
+		fmt.Printf("Backup written to %s\n", report.BackupPath)
+	}
+}
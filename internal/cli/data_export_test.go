@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"testing"
+)
+
+func TestParseImport(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		format  string
+		version int
+		ids     []int
+		wantErr bool
+	}{
+		{
+			name:    "versioned envelope",
+			input:   `{"version": 3, "entries": [{"id": 1, "minio_link": "http://m/1"}]}`,
+			format:  exportFormatJSON,
+			version: 3,
+			ids:     []int{1},
+		},
+		{
+			name:    "envelope without version",
+			input:   `{"entries": [{"id": 1, "minio_link": "http://m/1"}]}`,
+			format:  exportFormatJSON,
+			version: 0,
+			ids:     []int{1},
+		},
+		{
+			name:    "plain list",
+			input:   ` [{"id": 1, "minio_link": "http://m/1"}, {"id": 2, "minio_link": "http://m/2"}]`,
+			format:  exportFormatJSON,
+			version: 0,
+			ids:     []int{1, 2},
+		},
+		{
+			name:    "ndjson with blank lines",
+			input:   "{\"id\": 1, \"minio_link\": \"http://m/1\"}\n\n{\"id\": 2, \"minio_link\": \"http://m/2\"}\n",
+			format:  exportFormatNDJSON,
+			version: 0,
+			ids:     []int{1, 2},
+		},
+		{
+			name:    "invalid ndjson line",
+			input:   "{\"id\": 1}\nnot json\n",
+			format:  exportFormatNDJSON,
+			wantErr: true,
+		},
+		{
+			name:    "csv",
+			input:   "id,minio_link\n1,http://m/1\n",
+			format:  exportFormatCSV,
+			wantErr: true,
+		},
+		{
+			name:    "unknown format",
+			input:   "{}",
+			format:  "xml",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := parseImport([]byte(tt.input), tt.format)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if data.Version != tt.version {
+				t.Errorf("version = %d, want %d", data.Version, tt.version)
+			}
+
+			if len(data.Entries) != len(tt.ids) {
+				t.Fatalf("got %d entries, want %d", len(data.Entries), len(tt.ids))
+			}
+
+			for i, id := range tt.ids {
+				if data.Entries[i].ID != id {
+					t.Errorf("entry %d has id %d, want %d", i, data.Entries[i].ID, id)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+
+	"github.com/devusSs/minls/internal/log"
+	"github.com/devusSs/minls/internal/shortener"
+	"github.com/devusSs/minls/internal/storage"
+)
+
+// Delete removes the short link and the object of an entry on the backends
+// of the entry's profile and then the entry from the history.
+// The link goes first so it never points at a missing object.
+// The history is kept if a step fails so the command can be retried.
+func Delete() error {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	err := initialize()
+	if err != nil {
+		return fmt.Errorf("could not initialize: %w", err)
+	}
+
+	log.Debug("cli - Delete", slog.String("action", "initialized"))
+
+	fs := flag.NewFlagSet("delete", flag.ContinueOnError)
+	keepObject := fs.Bool("keep-object", false, "do not delete the object in minio")
+	keepLink := fs.Bool("keep-link", false, "do not delete the short link")
+
+	positional, err := parseFlags(fs, os.Args[2:])
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 1 {
+		return errors.New("usage: minls delete <id> [--keep-object] [--keep-link]")
+	}
+
+	entry, err := readEntryArg(positional[0])
+	if err != nil {
+		return err
+	}
+
+	if !*keepLink && hasShortLink(entry) {
+		err = deleteShortLink(ctx, entry)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !*keepObject {
+		mc, loc, objErr := entryObject(entry)
+		if objErr != nil {
+			return objErr
+		}
+
+		err = mc.RemoveObject(ctx, loc.Bucket, loc.Key)
+		if err != nil {
+			return fmt.Errorf("could not delete object of entry %d: %w", entry.ID, err)
+		}
+
+		log.Debug("cli - Delete", slog.String("action", "removed_object"), slog.Int("id", entry.ID))
+	}
+
+	err = storage.DeleteEntry(entry.ID)
+	if err != nil {
+		return fmt.Errorf("could not delete entry %d from history: %w", entry.ID, err)
+	}
+
+	fmt.Printf("Deleted entry %d (profile %s)\n", entry.ID, entryProfile(entry))
+
+	return nil
+}
+
+// hasShortLink reports whether the entry has a short link separate from its minio link.
+func hasShortLink(entry *storage.DataEntry) bool {
+	return entry.Shortener != shortener.NameNone && entry.YOURLSLink != "" && entry.YOURLSLink != entry.MinioLink
+}
+
+// deleteShortLink deletes the short link of the entry, links which are
+// already gone are fine, shorteners which cannot delete only produce a warning.
+func deleteShortLink(ctx context.Context, entry *storage.DataEntry) error {
+	sc, err := entryShortener(entry)
+	if err != nil {
+		return err
+	}
+
+	err = sc.Delete(ctx, entry.YOURLSLink)
+	switch {
+	case err == nil, errors.Is(err, shortener.ErrNotFound):
+		log.Debug("cli - deleteShortLink", slog.String("action", "deleted"), slog.Int("id", entry.ID))
+		return nil
+	case errors.Is(err, shortener.ErrNotSupported):
+		fmt.Fprintf(os.Stderr, "WARNING: %s cannot delete links, %s is kept\n", sc.Name(), entry.YOURLSLink)
+		return nil
+	default:
+		return fmt.Errorf("could not delete short link of entry %d: %w", entry.ID, err)
+	}
+}
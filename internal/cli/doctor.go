@@ -0,0 +1,550 @@
+package cli
+
+import (
+	"cmp"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/devusSs/minls/internal/clip"
+	"github.com/devusSs/minls/internal/config"
+	"github.com/devusSs/minls/internal/env"
+	"github.com/devusSs/minls/internal/log"
+	"github.com/devusSs/minls/internal/minio"
+	"github.com/devusSs/minls/internal/paths"
+	"github.com/devusSs/minls/internal/shortener"
+	"github.com/devusSs/minls/internal/yourls"
+)
+
+// Doctor checks the setup end to end and prints a report with a hint
+// per failed check. It does not use initialize since every step
+// initialize takes is one of the things being checked.
+func Doctor() error {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+
+	positional, err := parseFlags(fs, os.Args[2:])
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 0 {
+		return fmt.Errorf("unexpected args: %v", positional)
+	}
+
+	r := &doctorReport{Checks: make([]*doctorCheck, 0)}
+	r.run(ctx)
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+
+		err = enc.Encode(r)
+		if err != nil {
+			return fmt.Errorf("could not encode report: %w", err)
+		}
+	} else {
+		r.print()
+	}
+
+	if failed := r.failed(); failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(r.Checks))
+	}
+
+	return nil
+}
+
+const (
+	doctorPass = "pass"
+	doctorWarn = "warn"
+	doctorFail = "fail"
+	doctorSkip = "skip"
+)
+
+// doctorTimeout limits every check talking to a server.
+const doctorTimeout = 15 * time.Second
+
+type doctorCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	Hint   string `json:"hint,omitempty"`
+}
+
+type doctorReport struct {
+	Profile string         `json:"profile"`
+	Checks  []*doctorCheck `json:"checks"`
+}
+
+func (r *doctorReport) pass(name string, detail string) {
+	r.Checks = append(r.Checks, &doctorCheck{Name: name, Status: doctorPass, Detail: detail})
+}
+
+func (r *doctorReport) fail(name string, err error, hint string) {
+	r.Checks = append(r.Checks, &doctorCheck{Name: name, Status: doctorFail, Detail: err.Error(), Hint: hint})
+}
+
+// warn records a problem which does not break uploads, it does not fail the report.
+func (r *doctorReport) warn(name string, err error, hint string) {
+	r.Checks = append(r.Checks, &doctorCheck{Name: name, Status: doctorWarn, Detail: err.Error(), Hint: hint})
+}
+
+func (r *doctorReport) skip(name string, reason string) {
+	r.Checks = append(r.Checks, &doctorCheck{Name: name, Status: doctorSkip, Detail: reason})
+}
+
+func (r *doctorReport) failed() int {
+	return r.count(doctorFail)
+}
+
+func (r *doctorReport) count(status string) int {
+	n := 0
+	for _, c := range r.Checks {
+		if c.Status == status {
+			n++
+		}
+	}
+
+	return n
+}
+
+func (r *doctorReport) print() {
+	fmt.Printf("minls doctor - profile %s\n\n", r.Profile)
+
+	width := 0
+	for _, c := range r.Checks {
+		width = max(width, len(c.Name))
+	}
+
+	for _, c := range r.Checks {
+		fmt.Printf("%s  %-*s  %s\n", strings.ToUpper(c.Status), width, c.Name, c.Detail)
+
+		if c.Hint != "" {
+			fmt.Printf("      %-*s  hint: %s\n", width, "", c.Hint)
+		}
+	}
+
+	fmt.Println()
+
+	failed := r.failed()
+	warned := r.count(doctorWarn)
+
+	switch {
+	case failed > 0:
+		fmt.Printf("%d of %d checks failed.\n", failed, len(r.Checks))
+	case warned > 0:
+		fmt.Printf("All checks passed, %d with warnings.\n", warned)
+	default:
+		fmt.Println("All checks passed.")
+	}
+}
+
+func (r *doctorReport) run(ctx context.Context) {
+	configOK := r.checkConfig()
+	logsOK := r.checkDirs()
+
+	if logsOK {
+		err := log.Init()
+		if err != nil {
+			r.fail("log init", err, "check LOG_LEVEL and the log dir")
+		}
+	}
+
+	e := r.checkRequiredKeys(configOK)
+
+	if e != nil {
+		r.checkMinio(ctx, e)
+		r.checkShortener(ctx, e)
+	}
+
+	err := clip.Check()
+	if err != nil {
+		r.warn("clipboard", err, "links are still printed, only copying them fails")
+	} else {
+		r.pass("clipboard", "clipboard can be read")
+	}
+}
+
+func (r *doctorReport) checkConfig() bool {
+	cfg, err := config.Load()
+	if err != nil {
+		r.Profile = cmp.Or(os.Getenv(config.EnvProfile), config.DefaultProfile)
+		r.fail("config", err, "fix the file or key named in the error, see minls config show")
+		return false
+	}
+
+	r.Profile = cfg.Profile
+	activeConfig = cfg
+
+	addLogSecrets(cfg)
+
+	err = cfg.Apply()
+	if err != nil {
+		r.fail("config", err, "fix the value named in the error")
+		return false
+	}
+
+	sources := make(map[string]bool)
+	for _, v := range cfg.Values {
+		if v.IsSet() && v.Source != config.SourceDefault {
+			sources[v.Source] = true
+		}
+	}
+
+	if len(sources) == 0 {
+		r.fail("config", errors.New("nothing is configured"), "run minls init")
+		return false
+	}
+
+	found := make([]string, 0, len(sources))
+	for _, s := range []string{
+		config.SourceFlag,
+		config.SourceEnv,
+		config.SourceSecrets,
+		config.SourceDotEnv,
+		config.SourceProject,
+		config.SourceUser,
+	} {
+		if sources[s] {
+			found = append(found, s)
+		}
+	}
+
+	r.pass("config", "values from "+strings.Join(found, ", "))
+
+	return true
+}
+
+// checkDirs checks the data and log dirs are writable, it reports
+// whether the log dir is usable.
+func (r *doctorReport) checkDirs() bool {
+	dirs := []struct {
+		name  string
+		dir   func() (string, error)
+		flag  string
+		envID string
+	}{
+		{"data dir", paths.Data, "--data-dir", paths.EnvDataDir},
+		{"log dir", paths.Logs, "--log-dir", paths.EnvLogDir},
+	}
+
+	logsOK := true
+
+	for _, d := range dirs {
+		dir, err := d.dir()
+		if err == nil {
+			err = checkWritable(dir)
+		}
+
+		if err != nil {
+			r.fail(d.name, err, fmt.Sprintf("fix the permissions or use another dir with %s / %s", d.flag, d.envID))
+			logsOK = logsOK && d.name != "log dir"
+
+			continue
+		}
+
+		r.pass(d.name, dir+" is writable")
+	}
+
+	return logsOK
+}
+
+func checkWritable(dir string) error {
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return fmt.Errorf("could not create %s: %w", dir, err)
+	}
+
+	f, err := os.CreateTemp(dir, ".minls-doctor-*")
+	if err != nil {
+		return fmt.Errorf("could not write to %s: %w", dir, err)
+	}
+
+	f.Close()
+
+	return os.Remove(f.Name())
+}
+
+func (r *doctorReport) checkRequiredKeys(configOK bool) *env.Env {
+	if !configOK {
+		r.skip("required keys", "needs a working config")
+		return nil
+	}
+
+	e, err := loadEnv()
+	if err != nil {
+		r.fail("required keys", err, "run minls init or set the key named in the error")
+		return nil
+	}
+
+	r.pass("required keys", "minio and shortener "+e.Shortener+" are configured")
+
+	return e
+}
+
+func (r *doctorReport) checkMinio(ctx context.Context, e *env.Env) {
+	customTLS := e.MinioCABundle != "" || e.MinioInsecureSkipVerify
+
+	if !r.checkEndpoint(ctx, "minio", e.MinioEndpoint, "", "minio.endpoint", "minio.ca_bundle", customTLS) {
+		r.skip("minio credentials", "needs a reachable endpoint")
+		return
+	}
+
+	mc, err := newMinioClient(e)
+	if err != nil {
+		r.fail("minio credentials", err, "fix the minio settings named in the error")
+		return
+	}
+
+	tctx, cancel := context.WithTimeout(ctx, doctorTimeout)
+	defer cancel()
+
+	buckets, err := mc.ListBuckets(tctx)
+	if err != nil {
+		r.fail(
+			"minio credentials",
+			err,
+			"check minio.access_key and minio.access_secret (minls config set-secret minio.access_secret)",
+		)
+		return
+	}
+
+	r.pass("minio credentials", fmt.Sprintf("credentials can see %d bucket(s)", len(buckets)))
+
+	for _, public := range []bool{true, false} {
+		r.checkBucket(ctx, mc, mc.Bucket(public))
+	}
+
+	r.checkPublicPolicy(ctx, mc)
+}
+
+func (r *doctorReport) checkBucket(ctx context.Context, mc *minio.Client, bucket string) {
+	tctx, cancel := context.WithTimeout(ctx, doctorTimeout)
+	defer cancel()
+
+	name := "bucket " + bucket
+
+	exists, err := mc.BucketExists(tctx, bucket)
+	if err == nil && !exists {
+		err = errors.New("bucket does not exist")
+	}
+
+	if err != nil {
+		r.fail(name, err, "run minls init to create the buckets or fix minio.bucket_public / minio.bucket_private")
+		r.skip(name+" read/write", "needs the bucket")
+
+		return
+	}
+
+	r.pass(name, "bucket exists")
+
+	err = mc.Probe(tctx, bucket)
+	if err != nil {
+		r.fail(
+			name+" read/write",
+			err,
+			"the credentials need s3:PutObject, s3:GetObject and s3:DeleteObject on the bucket",
+		)
+
+		return
+	}
+
+	r.pass(name+" read/write", "put, get and delete of a probe object work")
+}
+
+func (r *doctorReport) checkPublicPolicy(ctx context.Context, mc *minio.Client) {
+	tctx, cancel := context.WithTimeout(ctx, doctorTimeout)
+	defer cancel()
+
+	public, err := mc.HasPublicPolicy(tctx)
+	if err == nil && !public {
+		err = errors.New("public bucket does not allow anonymous reads")
+	}
+
+	if err != nil {
+		r.fail(
+			"public policy",
+			err,
+			"public links will not work, run minls init or mc anonymous set download <alias>/"+mc.Bucket(true),
+		)
+
+		return
+	}
+
+	r.pass("public policy", "anonymous reads of "+mc.Bucket(true)+" are allowed")
+}
+
+func (r *doctorReport) checkShortener(ctx context.Context, e *env.Env) {
+	if e.Shortener != shortener.NameYOURLS {
+		r.skip("shortener", "only yourls is checked, configured is "+e.Shortener)
+		return
+	}
+
+	customTLS := e.YOURLSCABundle != "" || e.YOURLSPinnedCert != ""
+
+	if !r.checkEndpoint(
+		ctx,
+		"yourls",
+		e.YOURLSEndpoint,
+		e.YOURLSProxy,
+		"yourls.endpoint",
+		"yourls.ca_bundle",
+		customTLS,
+	) {
+		r.skip("yourls signature", "needs a reachable endpoint")
+		return
+	}
+
+	sc, err := newShortener(e)
+	if err != nil {
+		r.fail("yourls signature", err, "fix the yourls settings named in the error")
+		return
+	}
+
+	c, ok := sc.(*yourls.Client)
+	if !ok {
+		r.skip("yourls signature", "unexpected shortener type")
+		return
+	}
+
+	tctx, cancel := context.WithTimeout(ctx, doctorTimeout)
+	defer cancel()
+
+	stats, err := c.DBStats(tctx)
+	switch {
+	case errors.Is(err, yourls.ErrAuthFailed):
+		r.fail(
+			"yourls signature",
+			err,
+			"check yourls.signature (minls config set-secret yourls.signature) and yourls.auth_mode",
+		)
+	case err != nil:
+		r.fail("yourls signature", err, "check yourls.endpoint points at yourls-api.php")
+	default:
+		r.pass("yourls signature", fmt.Sprintf("accepted, the instance has %d link(s)", stats.Links))
+	}
+}
+
+// checkEndpoint checks the host of the endpoint resolves and, for https,
+// that its certificate is trusted. It reports whether the host resolves.
+// Both are skipped if requests go through a proxy (proxyURL or the
+// environment), only the proxy has to resolve and reach the host then.
+func (r *doctorReport) checkEndpoint(
+	ctx context.Context,
+	name string,
+	endpoint string,
+	proxyURL string,
+	key string,
+	caKey string,
+	customTLS bool,
+) bool {
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "http://" + endpoint
+	}
+
+	u, err := url.Parse(endpoint)
+	if err == nil && u.Hostname() == "" {
+		err = errors.New("endpoint does not contain a host")
+	}
+
+	if err != nil {
+		r.fail(name+" dns", err, "fix "+key)
+		return false
+	}
+
+	if proxy := endpointProxy(u, proxyURL); proxy != "" {
+		r.skip(name+" dns", "requests go through proxy "+proxy)
+		r.skip(name+" tls", "requests go through proxy "+proxy+", see the next check")
+
+		return true
+	}
+
+	tctx, cancel := context.WithTimeout(ctx, doctorTimeout)
+	defer cancel()
+
+	host := u.Hostname()
+
+	if net.ParseIP(host) != nil {
+		r.pass(name+" dns", host+" is an IP address")
+	} else {
+		addrs, lookupErr := net.DefaultResolver.LookupHost(tctx, host)
+		if lookupErr != nil {
+			r.fail(name+" dns", lookupErr, "check the host in "+key+" and your DNS / VPN")
+			return false
+		}
+
+		r.pass(name+" dns", host+" resolves to "+strings.Join(addrs, ", "))
+	}
+
+	switch {
+	case u.Scheme != "https":
+		r.skip(name+" tls", "endpoint uses plain http, traffic is not encrypted")
+	case customTLS:
+		r.skip(name+" tls", "custom TLS settings are used, see the next check")
+	default:
+		r.checkTLS(tctx, name, u, caKey)
+	}
+
+	return true
+}
+
+// endpointProxy returns the proxy requests to u go through without credentials,
+// proxyURL beats the HTTP_PROXY / HTTPS_PROXY / NO_PROXY environment variables.
+func endpointProxy(u *url.URL, proxyURL string) string {
+	var proxy *url.URL
+	var err error
+
+	if proxyURL != "" {
+		proxy, err = url.Parse(proxyURL)
+	} else {
+		proxy, err = http.ProxyFromEnvironment(&http.Request{URL: u})
+	}
+
+	if err != nil {
+		return "(invalid proxy url)"
+	}
+
+	if proxy == nil {
+		return ""
+	}
+
+	return proxy.Redacted()
+}
+
+func (r *doctorReport) checkTLS(ctx context.Context, name string, u *url.URL, caKey string) {
+	port := u.Port()
+	if port == "" {
+		port = "443"
+	}
+
+	d := &tls.Dialer{Config: &tls.Config{ServerName: u.Hostname(), MinVersion: tls.VersionTLS12}}
+
+	conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(u.Hostname(), port))
+	if err != nil {
+		r.fail(name+" tls", err, "check the server is up and its certificate is valid, or set "+caKey)
+		return
+	}
+	defer conn.Close()
+
+	tlsConn, ok := conn.(*tls.Conn)
+	if !ok || len(tlsConn.ConnectionState().PeerCertificates) == 0 {
+		r.pass(name+" tls", "handshake works")
+		return
+	}
+
+	cert := tlsConn.ConnectionState().PeerCertificates[0]
+	r.pass(name+" tls", "certificate valid until "+cert.NotAfter.Local().Format(time.DateOnly))
+}
@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+
+	"github.com/devusSs/minls/internal/log"
+	"github.com/devusSs/minls/internal/storage"
+)
+
+// Download downloads the object of an entry from the minio of the entry's profile.
+func Download() error {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	err := initialize()
+	if err != nil {
+		return fmt.Errorf("could not initialize: %w", err)
+	}
+
+	log.Debug("cli - Download", slog.String("action", "initialized"))
+
+	fs := flag.NewFlagSet("download", flag.ContinueOnError)
+	out := fs.String("out", "", "file or dir to write to (default: original name in the working dir)")
+	force := fs.Bool("force", false, "overwrite existing files")
+
+	positional, err := parseFlags(fs, os.Args[2:])
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 1 {
+		return errors.New("usage: minls download <id> [--out <path>] [--force]")
+	}
+
+	entry, err := readEntryArg(positional[0])
+	if err != nil {
+		return err
+	}
+
+	mc, loc, err := entryObject(entry)
+	if err != nil {
+		return err
+	}
+
+	name := filepath.Base(cmp.Or(entry.OriginalName, loc.Key))
+
+	path := cmp.Or(*out, name)
+	if fi, statErr := os.Stat(path); statErr == nil && fi.IsDir() {
+		path = filepath.Join(path, name)
+	}
+
+	if _, statErr := os.Stat(path); statErr == nil && !*force {
+		return fmt.Errorf("%s already exists, use --force to overwrite it", path)
+	}
+
+	err = mc.DownloadObject(ctx, loc.Bucket, loc.Key, path)
+	if err != nil {
+		return fmt.Errorf("could not download entry %d: %w", entry.ID, err)
+	}
+
+	fmt.Printf("Downloaded entry %d to %s\n", entry.ID, path)
+
+	return nil
+}
+
+// readEntryArg reads the entry with the id given as argument.
+func readEntryArg(arg string) (*storage.DataEntry, error) {
+	id, err := strconv.Atoi(arg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id provided: %w", err)
+	}
+
+	entry, err := storage.ReadEntry(id)
+	if err != nil {
+		return nil, fmt.Errorf("could not read entry %d: %w", id, err)
+	}
+
+	return entry, nil
+}
@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// parseFlags parses args using fs and returns the positional arguments.
+// Unlike fs.Parse flags may appear before, between or after positional arguments.
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	positional := make([]string, 0)
+
+	for {
+		err := fs.Parse(args)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse flags: %w", err)
+		}
+
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// stringList is a flag which can be passed multiple times.
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringList) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
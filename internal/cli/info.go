@@ -0,0 +1,217 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/devusSs/minls/internal/env"
+	"github.com/devusSs/minls/internal/log"
+	"github.com/devusSs/minls/internal/minio"
+	"github.com/devusSs/minls/internal/shortener"
+	"github.com/devusSs/minls/internal/storage"
+)
+
+func Info() error {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	err := initialize()
+	if err != nil {
+		return fmt.Errorf("could not initialize: %w", err)
+	}
+
+	log.Debug("cli - Info", slog.String("action", "initialized"))
+
+	fs := flag.NewFlagSet("info", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print the info as JSON")
+
+	positional, err := parseFlags(fs, os.Args[2:])
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 1 {
+		return errors.New("missing id")
+	}
+
+	id, err := strconv.Atoi(positional[0])
+	if err != nil {
+		return fmt.Errorf("invalid id provided: %w", err)
+	}
+
+	entry, err := storage.ReadEntry(id)
+	if err != nil {
+		return fmt.Errorf("could not read entry %d: %w", id, err)
+	}
+
+	env, err := entryEnv(entry)
+	if err != nil {
+		return fmt.Errorf("could not load env: %w", err)
+	}
+
+	report := collectInfo(ctx, env, entry)
+
+	log.Debug("cli - Info", slog.String("action", "collected_info"), slog.Any("report", report))
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+
+	return printInfo(report)
+}
+
+// infoReport combines the local entry with the live remote state,
+// remote failures are recorded instead of failing the whole report.
+type infoReport struct {
+	Entry         *storage.DataEntry       `json:"entry"`
+	Object        *minio.ObjectInfo        `json:"object,omitempty"`
+	ObjectError   string                   `json:"object_error,omitempty"`
+	Stats         *shortener.Stats         `json:"stats,omitempty"`
+	StatsError    string                   `json:"stats_error,omitempty"`
+	Redirect      *shortener.RedirectCheck `json:"redirect,omitempty"`
+	RedirectError string                   `json:"redirect_error,omitempty"`
+	Expiry        string                   `json:"expiry"`
+}
+
+func collectInfo(ctx context.Context, e *env.Env, entry *storage.DataEntry) *infoReport {
+	report := &infoReport{Entry: entry, Expiry: describeExpiry(entry.MinioLink)}
+
+	report.Object, report.ObjectError = statEntryObject(ctx, e, entry)
+
+	sc, err := newShortener(e)
+	if err != nil {
+		report.StatsError = err.Error()
+		return report
+	}
+
+	if sc.Name() == shortener.NameNone || entry.YOURLSLink == entry.MinioLink {
+		report.StatsError = "link was not shortened"
+		return report
+	}
+
+	report.Stats, err = sc.Stats(ctx, entry.YOURLSLink)
+	if err != nil {
+		report.StatsError = err.Error()
+	}
+
+	report.Redirect, err = shortener.CheckRedirect(ctx, entry.YOURLSLink, entry.MinioLink)
+	if err != nil {
+		report.RedirectError = err.Error()
+	}
+
+	return report
+}
+
+func statEntryObject(
+	ctx context.Context,
+	e *env.Env,
+	entry *storage.DataEntry,
+) (*minio.ObjectInfo, string) {
+	mc, err := newMinioClient(e)
+	if err != nil {
+		return nil, err.Error()
+	}
+
+	loc, err := entryLocation(mc, entry)
+	if err != nil {
+		return nil, err.Error()
+	}
+
+	info, err := mc.StatObject(ctx, loc.Bucket, loc.Key)
+	if err != nil {
+		return nil, err.Error()
+	}
+
+	return info, ""
+}
+
+func printInfo(r *infoReport) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+
+	fmt.Fprintln(w, "Local")
+	fmt.Fprintf(w, "  ID:\t%d\n", r.Entry.ID)
+	fmt.Fprintf(w, "  Timestamp:\t%s\n", r.Entry.Timestamp.Format(time.DateTime))
+	fmt.Fprintf(w, "  Original name:\t%s\n", r.Entry.OriginalName)
+	fmt.Fprintf(w, "  Policy:\t%s\n", r.Entry.Policy)
+	fmt.Fprintf(w, "  Profile:\t%s\n", entryProfile(r.Entry))
+	fmt.Fprintf(w, "  Short link:\t%s\n", r.Entry.YOURLSLink)
+	fmt.Fprintf(w, "  Minio link:\t%s\n", r.Entry.MinioLink)
+	fmt.Fprintf(w, "  Expiry:\t%s\n", r.Expiry)
+	fmt.Fprintf(w, "  Archived:\t%t\n", r.Entry.Archived)
+	fmt.Fprintf(w, "  Tags:\t%s\n", strings.Join(r.Entry.Tags, ", "))
+	fmt.Fprintf(w, "  Note:\t%s\n", r.Entry.Note)
+
+	fmt.Fprintln(w, "MinIO")
+	if r.Object == nil {
+		fmt.Fprintf(w, "  Error:\t%s\n", r.ObjectError)
+	} else {
+		printInfoObject(w, r.Object)
+	}
+
+	fmt.Fprintln(w, "Short link")
+	if r.Stats == nil {
+		fmt.Fprintf(w, "  Stats:\tunavailable (%s)\n", r.StatsError)
+	} else {
+		fmt.Fprintf(w, "  Clicks:\t%d\n", r.Stats.Clicks)
+		fmt.Fprintf(w, "  Title:\t%s\n", r.Stats.Title)
+	}
+
+	switch {
+	case r.Redirect != nil && r.Redirect.OK:
+		fmt.Fprintf(w, "  Redirect:\tok (%d)\n", r.Redirect.StatusCode)
+	case r.Redirect != nil:
+		fmt.Fprintf(
+			w,
+			"  Redirect:\tMISMATCH (%d to %q)\n",
+			r.Redirect.StatusCode,
+			r.Redirect.Location,
+		)
+	case r.RedirectError != "":
+		fmt.Fprintf(w, "  Redirect:\tunknown (%s)\n", r.RedirectError)
+	}
+
+	return w.Flush()
+}
+
+func printInfoObject(w *tabwriter.Writer, o *minio.ObjectInfo) {
+	fmt.Fprintf(w, "  Object:\t%s/%s\n", o.Bucket, o.Key)
+	fmt.Fprintf(w, "  Size:\t%d bytes\n", o.Size)
+	fmt.Fprintf(w, "  ETag:\t%s\n", o.ETag)
+	fmt.Fprintf(w, "  Content type:\t%s\n", o.ContentType)
+	fmt.Fprintf(w, "  Last modified:\t%s\n", o.LastModified.Local().Format(time.DateTime))
+
+	for _, k := range slices.Sorted(maps.Keys(o.UserMetadata)) {
+		fmt.Fprintf(w, "  Metadata %s:\t%s\n", k, o.UserMetadata[k])
+	}
+
+	if o.RetentionMode == "" {
+		fmt.Fprintln(w, "  Retention:\tnone")
+	} else {
+		fmt.Fprintf(
+			w,
+			"  Retention:\t%s until %s\n",
+			o.RetentionMode,
+			o.RetainUntil.Local().Format(time.DateTime),
+		)
+	}
+
+	if o.LegalHold == "" {
+		fmt.Fprintln(w, "  Legal hold:\tnone")
+	} else {
+		fmt.Fprintf(w, "  Legal hold:\t%s\n", o.LegalHold)
+	}
+}
@@ -0,0 +1,413 @@
+package cli
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strconv"
+
+	"github.com/devusSs/minls/internal/config"
+	"github.com/devusSs/minls/internal/log"
+	"github.com/devusSs/minls/internal/minio"
+	"github.com/devusSs/minls/internal/secrets"
+	"github.com/devusSs/minls/internal/shlink"
+	"github.com/devusSs/minls/internal/shortener"
+	"github.com/devusSs/minls/internal/yourls"
+)
+
+// Init asks for the MinIO and shortener settings, validates them with real
+// requests, creates the buckets and writes the config of the profile.
+// Secrets go to the secret store, everything else to the user config file.
+func Init() error {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	// the profile to set up may not exist yet, which initialize would reject
+	profile := os.Getenv(config.EnvProfile)
+
+	err := os.Unsetenv(config.EnvProfile)
+	if err != nil {
+		return fmt.Errorf("could not unset %s: %w", config.EnvProfile, err)
+	}
+
+	err = initialize()
+	if err != nil {
+		return fmt.Errorf("could not initialize: %w", err)
+	}
+
+	profile = cmp.Or(profile, activeProfile)
+
+	log.Debug("cli - Init", slog.String("action", "initialize"), slog.String("profile", profile))
+
+	if len(os.Args) > initMaxArgs {
+		return fmt.Errorf("unexpected args: %v", os.Args[initMaxArgs:])
+	}
+
+	values := currentValues(profile)
+	p := newPrompter()
+
+	fmt.Printf("Setting up profile %s, press enter to keep the value in brackets.\n\n", profile)
+
+	mc, err := initMinio(ctx, p, values)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+
+	err = initShortener(ctx, p, values)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+
+	err = mc.CreateBuckets(ctx)
+	if err != nil {
+		return fmt.Errorf("could not create buckets: %w", err)
+	}
+
+	fmt.Printf("Buckets %s and %s are ready.\n", mc.Bucket(true), mc.Bucket(false))
+
+	return writeInitConfig(profile, values)
+}
+
+const initMaxArgs = 2
+
+// initKeys returns the keys the wizard asked for with the chosen shortener and auth mode.
+func initKeys(values map[string]string) []string {
+	keys := []string{
+		"minio.endpoint",
+		"minio.access_key",
+		"minio.access_secret",
+		"minio.bucket_public",
+		"minio.bucket_private",
+		"shortener.name",
+	}
+
+	switch values["shortener.name"] {
+	case shortener.NameYOURLS:
+		keys = append(keys, "yourls.endpoint", "yourls.auth_mode")
+
+		if values["yourls.auth_mode"] == yourls.AuthModePassword {
+			keys = append(keys, "yourls.username", "yourls.password")
+		} else {
+			keys = append(keys, "yourls.signature")
+		}
+	case shortener.NameShlink:
+		keys = append(keys, "shlink.endpoint", "shlink.api_key")
+	}
+
+	return keys
+}
+
+// currentValues returns the configured values of the profile as answer defaults,
+// new profiles start with the defaults.
+func currentValues(profile string) map[string]string {
+	values := make(map[string]string)
+
+	cfg, err := config.LoadProfile(profile)
+	if err != nil {
+		log.Debug("cli - currentValues", slog.String("warn", "new profile"), slog.Any("err", err))
+
+		for _, k := range config.Keys {
+			values[k.Name] = k.Default
+		}
+
+		return values
+	}
+
+	for _, v := range cfg.Values {
+		values[v.Name] = v.Value
+	}
+
+	return values
+}
+
+// initMinio asks for the MinIO settings until listing the buckets works.
+func initMinio(ctx context.Context, p *prompter, values map[string]string) (*minio.Client, error) {
+	fmt.Println("MinIO")
+
+	for {
+		err := askValues(p, values, []initQuestion{
+			{"minio.endpoint", "Endpoint (e.g. https://minio.example.com)"},
+			{"minio.access_key", "Access key"},
+			{"minio.access_secret", "Access secret"},
+			{"minio.bucket_public", "Public bucket"},
+			{"minio.bucket_private", "Private bucket"},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		mc, err := initMinioClient(values)
+		if err == nil {
+			var buckets []string
+			buckets, err = mc.ListBuckets(ctx)
+			if err == nil {
+				fmt.Printf("OK, the credentials can see %d bucket(s).\n", len(buckets))
+				return mc, nil
+			}
+		}
+
+		fmt.Println("Could not connect to MinIO:", err)
+
+		err = retryOrAbort(p)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func initMinioClient(values map[string]string) (*minio.Client, error) {
+	insecure, err := strconv.ParseBool(cmp.Or(values["minio.insecure_skip_verify"], "false"))
+	if err != nil {
+		return nil, fmt.Errorf("could not parse minio.insecure_skip_verify: %w", err)
+	}
+
+	return minio.NewClient(&minio.Config{
+		Endpoint:           values["minio.endpoint"],
+		AccessKey:          values["minio.access_key"],
+		AccessSecret:       values["minio.access_secret"],
+		CABundle:           values["minio.ca_bundle"],
+		ClientCert:         values["minio.client_cert"],
+		ClientKey:          values["minio.client_key"],
+		InsecureSkipVerify: insecure,
+		BucketLookup:       cmp.Or(values["minio.bucket_lookup"], minio.BucketLookupAuto),
+		PublicBucket:       values["minio.bucket_public"],
+		PrivateBucket:      values["minio.bucket_private"],
+	})
+}
+
+// initShortener asks which shortener to use, keeping the configured one by default.
+func initShortener(ctx context.Context, p *prompter, values map[string]string) error {
+	fmt.Println("Shortener")
+
+	name, err := p.choose(
+		"Shortener",
+		values["shortener.name"],
+		[]string{shortener.NameYOURLS, shortener.NameShlink, shortener.NameNone},
+	)
+	if err != nil {
+		return err
+	}
+
+	values["shortener.name"] = name
+
+	switch name {
+	case shortener.NameYOURLS:
+		return initYOURLS(ctx, p, values)
+	case shortener.NameShlink:
+		return initShlink(ctx, p, values)
+	default:
+		fmt.Println("OK, links are not shortened.")
+		return nil
+	}
+}
+
+// initYOURLS asks for the YOURLS settings until a db-stats request works.
+func initYOURLS(ctx context.Context, p *prompter, values map[string]string) error {
+	for {
+		mode, err := p.choose(
+			"Auth mode",
+			values["yourls.auth_mode"],
+			[]string{yourls.AuthModeSignature, yourls.AuthModeTimedSignature, yourls.AuthModePassword},
+		)
+		if err != nil {
+			return err
+		}
+
+		values["yourls.auth_mode"] = mode
+
+		questions := []initQuestion{{"yourls.endpoint", "API endpoint (e.g. https://sho.rt/yourls-api.php)"}}
+		if mode == yourls.AuthModePassword {
+			questions = append(
+				questions,
+				initQuestion{"yourls.username", "Username"},
+				initQuestion{"yourls.password", "Password"},
+			)
+		} else {
+			questions = append(questions, initQuestion{"yourls.signature", "Signature token"})
+		}
+
+		err = askValues(p, values, questions)
+		if err != nil {
+			return err
+		}
+
+		var stats *yourls.DBStats
+
+		c, err := yourls.NewClient(
+			values["yourls.endpoint"],
+			initYOURLSAuth(values),
+			&yourls.TransportConfig{
+				Timeout:          yourls.DefaultTimeout,
+				ProxyURL:         values["yourls.proxy"],
+				CABundle:         values["yourls.ca_bundle"],
+				PinnedCertSHA256: values["yourls.pinned_cert"],
+			},
+		)
+		if err == nil {
+			stats, err = c.DBStats(ctx)
+			if err == nil {
+				fmt.Printf("OK, the instance has %d link(s).\n", stats.Links)
+				return nil
+			}
+		}
+
+		fmt.Println("Could not connect to YOURLS:", err)
+
+		err = retryOrAbort(p)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func initYOURLSAuth(values map[string]string) yourls.Auth {
+	switch values["yourls.auth_mode"] {
+	case yourls.AuthModePassword:
+		return &yourls.PasswordAuth{Username: values["yourls.username"], Password: values["yourls.password"]}
+	case yourls.AuthModeTimedSignature:
+		return &yourls.TimedSignatureAuth{Signature: values["yourls.signature"], Hash: values["yourls.signature_hash"]}
+	default:
+		return &yourls.SignatureAuth{Signature: values["yourls.signature"]}
+	}
+}
+
+// initShlinkCheckCode is looked up to validate the API key,
+// a not found answer means the key was accepted.
+const initShlinkCheckCode = "minls-init-check"
+
+// initShlink asks for the Shlink settings until an authenticated request works.
+func initShlink(ctx context.Context, p *prompter, values map[string]string) error {
+	for {
+		err := askValues(p, values, []initQuestion{
+			{"shlink.endpoint", "Endpoint (e.g. https://s.example.com)"},
+			{"shlink.api_key", "API key"},
+		})
+		if err != nil {
+			return err
+		}
+
+		c := shlink.NewClient(values["shlink.endpoint"], values["shlink.api_key"])
+
+		_, err = c.Stats(ctx, initShlinkCheckCode)
+		if err == nil || errors.Is(err, shortener.ErrNotFound) {
+			fmt.Println("OK, the API key was accepted.")
+			return nil
+		}
+
+		fmt.Println("Could not connect to Shlink:", err)
+
+		err = retryOrAbort(p)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+type initQuestion struct {
+	key   string
+	label string
+}
+
+func askValues(p *prompter, values map[string]string, questions []initQuestion) error {
+	for _, q := range questions {
+		k := config.FindKey(q.key)
+
+		var v string
+		var err error
+
+		if k.Secret {
+			v, err = p.askSecret(q.label, values[q.key])
+		} else {
+			v, err = p.ask(q.label, values[q.key])
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if v == "" {
+			return fmt.Errorf("%s is required", q.key)
+		}
+
+		if k.Secret {
+			log.AddSecret(v)
+		}
+
+		values[q.key] = v
+	}
+
+	return nil
+}
+
+var errInitAborted = errors.New("setup aborted, nothing was written")
+
+func retryOrAbort(p *prompter) error {
+	retry, err := p.confirm("Try again?", true)
+	if err != nil {
+		return err
+	}
+
+	if !retry {
+		return errInitAborted
+	}
+
+	return nil
+}
+
+// writeInitConfig writes the plain values to the user config file
+// and then the secrets to the secret store, so a failing store
+// never leaves secrets behind without a config using them.
+func writeInitConfig(profile string, values map[string]string) error {
+	keys := initKeys(values)
+	plain := make(map[string]string)
+
+	for _, name := range keys {
+		if !config.FindKey(name).Secret {
+			plain[name] = values[name]
+		}
+	}
+
+	path, err := config.WriteUser(profile, plain)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Wrote the config of profile %s to %s.\n", profile, path)
+
+	store, err := config.SecretStore()
+	if err != nil {
+		return fmt.Errorf("%w, store the secrets with minls config set-secret", err)
+	}
+
+	for _, name := range keys {
+		k := config.FindKey(name)
+		if !k.Secret {
+			continue
+		}
+
+		err = store.Set(profile, k.Env, values[name])
+		if err != nil {
+			return fmt.Errorf(
+				"could not store %s in %s (see %s), store it with minls config set-secret: %w",
+				name,
+				store.Name(),
+				secrets.EnvBackend,
+				err,
+			)
+		}
+	}
+
+	fmt.Printf("Stored the secrets in %s.\n", store.Location())
+	fmt.Println()
+	fmt.Println("Try it with: minls upload <file>")
+
+	return nil
+}
@@ -1,18 +1,27 @@
 package cli
 
 import (
+	"cmp"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log/slog"
-	"net/url"
 	"os"
-	"path/filepath"
-	"text/tabwriter"
+	"os/signal"
+	"path"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/devusSs/minls/internal/log"
 	"github.com/devusSs/minls/internal/storage"
 )
 
 func List() error {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
 	err := initialize()
 	if err != nil {
 		return fmt.Errorf("could not initialize: %w", err)
@@ -20,44 +29,287 @@ func List() error {
 
 	log.Debug("cli - List", slog.String("action", "initialize"))
 
-	data, err := storage.ReadData()
+	args, err := parseListArgs(os.Args[2:])
+	if err != nil {
+		return fmt.Errorf("could not parse list args: %w", err)
+	}
+
+	log.Debug("cli - List", slog.String("action", "parsed_args"), slog.Any("args", args))
+
+	entries, err := storage.ReadRange(args.since, args.until)
 	if err != nil {
 		return fmt.Errorf("could not read data from storage: %w", err)
 	}
 
-	log.Debug("cli - List", slog.String("action", "storage_read_data"), slog.Any("data", data))
+	log.Debug("cli - List", slog.String("action", "storage_read_range"), slog.Int("entries", len(entries)))
 
-	if len(data.Entries) == 0 {
-		fmt.Println("No data to be displayed yet.")
-		return nil
+	rows := make([]*listRow, 0, len(entries))
+	for _, entry := range entries {
+		if args.matches(entry) {
+			rows = append(rows, newListRow(entry))
+		}
 	}
 
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
-	fmt.Fprintln(w, "ID\tTimestamp\tMinio ID\tYOURLS ID")
+	// clicks cost a request per entry, only sorting by them needs them for every row
+	sortByClicks := args.sort == "clicks"
+	if sortByClicks {
+		loadListClicks(ctx, rows)
+	}
 
-	for _, entry := range data.Entries {
-		var minioURL *url.URL
-		var yourlsURL *url.URL
+	err = sortListRows(rows, args.sort, args.reverse)
+	if err != nil {
+		return err
+	}
 
-		minioURL, err = url.Parse(entry.MinioLink)
-		if err != nil {
-			return fmt.Errorf("malformed minio url: %w", err)
+	rows = paginate(rows, args.limit, args.page)
+
+	if !sortByClicks && args.showsClicks() {
+		loadListClicks(ctx, rows)
+	}
+
+	err = writeList(os.Stdout, args.format, args.columns, rows)
+	if err != nil {
+		return fmt.Errorf("could not write list: %w", err)
+	}
+
+	// warnings go to stderr so they do not break json / csv output
+	for _, row := range rows {
+		for _, warning := range row.warnings {
+			log.Debug("cli - List", slog.Int("id", row.entry.ID), slog.String("warn", warning))
+			fmt.Fprintf(os.Stderr, "WARNING: entry %d: %s\n", row.entry.ID, warning)
 		}
+	}
 
-		yourlsURL, err = url.Parse(entry.YOURLSLink)
+	return nil
+}
+
+type listArgs struct {
+	all     bool
+	since   time.Time
+	until   time.Time
+	policy  string
+	ctype   string
+	name    string
+	sort    string
+	reverse bool
+	columns []*listColumn
+	limit   int
+	page    int
+	format  string
+}
+
+func parseListArgs(args []string) (*listArgs, error) {
+	la := &listArgs{}
+
+	var since, until, columns string
+	var asJSON, asCSV, asMarkdown bool
+
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	fs.BoolVar(&la.all, "all", false, "also show archived entries")
+	fs.StringVar(&since, "since", "", "only entries uploaded since (date, time or age like 7d / 12h)")
+	fs.StringVar(&until, "until", "", "only entries uploaded before (date, time or age like 7d / 12h)")
+	fs.StringVar(&la.policy, "policy", "", "only entries with the policy (public / private)")
+	fs.StringVar(&la.ctype, "type", "", "only entries with a matching content type (e.g. image/*)")
+	fs.StringVar(&la.name, "name", "", "only entries with a matching original name (glob)")
+	fs.StringVar(&la.sort, "sort", "id", "sort by "+strings.Join(listSortKeys, " / "))
+	fs.BoolVar(&la.reverse, "reverse", false, "reverse the sort order")
+	fs.StringVar(&columns, "columns", defaultListColumns, "comma separated columns ("+listColumnNames()+")")
+	fs.IntVar(&la.limit, "limit", 0, "entries per page, 0 shows all")
+	fs.IntVar(&la.page, "page", 1, "page to show if --limit is set")
+	fs.StringVar(&la.format, "format", listFormatTable, "output format (table / json / csv / markdown)")
+	fs.BoolVar(&asJSON, "json", false, "shorthand for --format json")
+	fs.BoolVar(&asCSV, "csv", false, "shorthand for --format csv")
+	fs.BoolVar(&asMarkdown, "markdown", false, "shorthand for --format markdown")
+
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(positional) != 0 {
+		return nil, fmt.Errorf("unexpected args: %v", positional)
+	}
+
+	switch {
+	case asJSON:
+		la.format = listFormatJSON
+	case asCSV:
+		la.format = listFormatCSV
+	case asMarkdown:
+		la.format = listFormatMarkdown
+	}
+
+	now := time.Now()
+
+	la.since, err = parseTimeFlag(since, now)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --since: %w", err)
+	}
+
+	la.until, err = parseTimeFlag(until, now)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --until: %w", err)
+	}
+
+	la.columns, err = parseListColumns(columns, la.all)
+	if err != nil {
+		return nil, err
+	}
+
+	if la.limit < 0 || la.page < 1 {
+		return nil, errors.New("--limit must not be negative and --page must be at least 1")
+	}
+
+	return la, nil
+}
+
+// parseTimeFlag parses an absolute time (RFC 3339, date time or date)
+// or an age relative to now (e.g. 7d or 12h). Empty strings return the zero time.
+func parseTimeFlag(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	for _, layout := range []string{time.RFC3339, time.DateTime, time.DateOnly} {
+		t, err := time.ParseInLocation(layout, s, time.Local)
+		if err == nil {
+			return t, nil
+		}
+	}
+
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		d, err := time.ParseDuration(days + "h")
+		if err == nil {
+			return now.Add(-d * hoursPerDay), nil
+		}
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("could not parse %q as time or age", s)
+	}
+
+	return now.Add(-d), nil
+}
+
+const hoursPerDay = 24
+
+func (la *listArgs) matches(entry *storage.DataEntry) bool {
+	if entry.Archived && !la.all {
+		return false
+	}
+
+	if la.policy != "" && entry.Policy != la.policy {
+		return false
+	}
+
+	if la.ctype != "" && !globMatch(la.ctype, entry.ContentType) {
+		return false
+	}
+
+	if la.name != "" && !globMatch(la.name, entry.OriginalName) {
+		return false
+	}
+
+	return true
+}
+
+func globMatch(pattern string, s string) bool {
+	ok, err := path.Match(pattern, s)
+	return err == nil && ok
+}
+
+func (la *listArgs) showsClicks() bool {
+	return slices.ContainsFunc(la.columns, func(c *listColumn) bool { return c.name == "clicks" })
+}
+
+// listRow is an entry with data which is not stored in the history.
+type listRow struct {
+	entry    *storage.DataEntry
+	clicks   *int64
+	warnings []string
+}
+
+func newListRow(entry *storage.DataEntry) *listRow {
+	return &listRow{entry: entry, warnings: make([]string, 0)}
+}
+
+// loadListClicks loads click counts, failures only produce warnings.
+func loadListClicks(ctx context.Context, rows []*listRow) {
+	shorteners := newShortenerCache()
+
+	for _, row := range rows {
+		sc, err := shorteners.forEntry(row.entry)
 		if err != nil {
-			return fmt.Errorf("mailformed yourls url: %w", err)
+			row.warnings = append(row.warnings, "could not get clicks: "+err.Error())
+			continue
+		}
+
+		if row.entry.Shortener != "" && row.entry.Shortener != sc.Name() {
+			row.warnings = append(row.warnings, "created with shortener "+row.entry.Shortener)
+			continue
 		}
 
-		fmt.Fprintf(
-			w,
-			"%d\t%s\t%s\t%s\n",
-			entry.ID,
-			entry.Timestamp.Format("2006-01-02 15:04:05"),
-			filepath.Base(minioURL.Path),
-			filepath.Base(yourlsURL.Path),
-		)
+		s, statsErr := sc.Stats(ctx, row.entry.YOURLSLink)
+		if statsErr != nil {
+			row.warnings = append(row.warnings, "could not get clicks: "+statsErr.Error())
+			continue
+		}
+
+		row.clicks = &s.Clicks
+	}
+}
+
+var listSortKeys = []string{"id", "time", "size", "name", "expiry", "clicks"}
+
+func sortListRows(rows []*listRow, key string, reverse bool) error {
+	var cmpFunc func(a *listRow, b *listRow) int
+
+	switch key {
+	case "id":
+		cmpFunc = func(a *listRow, b *listRow) int { return cmp.Compare(a.entry.ID, b.entry.ID) }
+	case "time":
+		cmpFunc = func(a *listRow, b *listRow) int { return a.entry.Timestamp.Compare(b.entry.Timestamp) }
+	case "size":
+		cmpFunc = func(a *listRow, b *listRow) int { return cmp.Compare(a.entry.Size, b.entry.Size) }
+	case "name":
+		cmpFunc = func(a *listRow, b *listRow) int {
+			return strings.Compare(a.entry.OriginalName, b.entry.OriginalName)
+		}
+	case "expiry":
+		cmpFunc = func(a *listRow, b *listRow) int { return a.entry.ExpiresAt.Compare(b.entry.ExpiresAt) }
+	case "clicks":
+		cmpFunc = func(a *listRow, b *listRow) int {
+			return cmp.Compare(derefOr(a.clicks, -1), derefOr(b.clicks, -1))
+		}
+	default:
+		return fmt.Errorf("unknown sort key %q (options: %s)", key, strings.Join(listSortKeys, " / "))
 	}
 
-	return w.Flush()
+	slices.SortStableFunc(rows, cmpFunc)
+
+	if reverse {
+		slices.Reverse(rows)
+	}
+
+	return nil
+}
+
+func derefOr[T any](p *T, def T) T {
+	if p == nil {
+		return def
+	}
+
+	return *p
+}
+
+func paginate(rows []*listRow, limit int, page int) []*listRow {
+	if limit == 0 {
+		return rows
+	}
+
+	start := min((page-1)*limit, len(rows))
+	end := min(start+limit, len(rows))
+
+	return rows[start:end]
 }
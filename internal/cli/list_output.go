@@ -0,0 +1,266 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+type listColumn struct {
+	name   string
+	header string
+	value  func(row *listRow) string
+}
+
+const defaultListColumns = "id,time,minio,short"
+
+var listColumns = []*listColumn{
+	{"id", "ID", func(r *listRow) string { return strconv.Itoa(r.entry.ID) }},
+	{"time", "Timestamp", func(r *listRow) string { return r.entry.Timestamp.Format(time.DateTime) }},
+	{"minio", "Minio ID", func(r *listRow) string { return linkBase(r, r.entry.MinioLink) }},
+	{"short", "Short ID", func(r *listRow) string { return linkBase(r, r.entry.YOURLSLink) }},
+	{"minio_link", "Minio link", func(r *listRow) string { return r.entry.MinioLink }},
+	{"short_link", "Short link", func(r *listRow) string { return r.entry.YOURLSLink }},
+	{"name", "Original name", func(r *listRow) string { return r.entry.OriginalName }},
+	{"type", "Content type", func(r *listRow) string { return r.entry.ContentType }},
+	{"size", "Size", func(r *listRow) string { return formatSize(r.entry.Size) }},
+	{"policy", "Policy", func(r *listRow) string { return r.entry.Policy }},
+	{"keyword", "Keyword", func(r *listRow) string { return r.entry.Keyword }},
+	{"title", "Title", func(r *listRow) string { return r.entry.Title }},
+	{"tags", "Tags", func(r *listRow) string { return strings.Join(r.entry.Tags, ",") }},
+	{"note", "Note", func(r *listRow) string { return r.entry.Note }},
+	{"profile", "Profile", func(r *listRow) string { return entryProfile(r.entry) }},
+	{"expiry", "Expiry", formatListExpiry},
+	{"clicks", "Clicks", func(r *listRow) string {
+		if r.clicks == nil {
+			return ""
+		}
+
+		return strconv.FormatInt(*r.clicks, 10)
+	}},
+	{"archived", "Archived", func(r *listRow) string { return strconv.FormatBool(r.entry.Archived) }},
+}
+
+func listColumnNames() string {
+	names := make([]string, 0, len(listColumns))
+	for _, c := range listColumns {
+		names = append(names, c.name)
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// parseListColumns parses the comma separated column names,
+// the archived column is added if archived entries are shown.
+func parseListColumns(s string, all bool) ([]*listColumn, error) {
+	columns := make([]*listColumn, 0)
+
+	for name := range strings.SplitSeq(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		c := findListColumn(name)
+		if c == nil {
+			return nil, fmt.Errorf("unknown column %q (options: %s)", name, listColumnNames())
+		}
+
+		columns = append(columns, c)
+	}
+
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("no columns selected (options: %s)", listColumnNames())
+	}
+
+	if all && s == defaultListColumns {
+		columns = append(columns, findListColumn("archived"))
+	}
+
+	return columns, nil
+}
+
+func findListColumn(name string) *listColumn {
+	for _, c := range listColumns {
+		if c.name == name {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// linkBase returns the last path element of the link,
+// malformed links produce a warning instead of failing the listing.
+func linkBase(r *listRow, link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		r.warnings = append(r.warnings, "malformed link: "+err.Error())
+		return "?"
+	}
+
+	return path.Base(u.Path)
+}
+
+func formatListExpiry(r *listRow) string {
+	if r.entry.ExpiresAt.IsZero() {
+		if r.entry.Policy == "public" {
+			return "never"
+		}
+
+		return ""
+	}
+
+	return r.entry.ExpiresAt.Local().Format(time.DateTime)
+}
+
+const sizeUnit = 1024
+
+func formatSize(size int64) string {
+	if size <= 0 {
+		return ""
+	}
+
+	if size < sizeUnit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := int64(sizeUnit), 0
+	for n := size / sizeUnit; n >= sizeUnit; n /= sizeUnit {
+		div *= sizeUnit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+const (
+	listFormatTable    = "table"
+	listFormatJSON     = "json"
+	listFormatCSV      = "csv"
+	listFormatMarkdown = "markdown"
+)
+
+func writeList(w io.Writer, format string, columns []*listColumn, rows []*listRow) error {
+	switch format {
+	case listFormatTable:
+		if len(rows) == 0 {
+			fmt.Fprintln(w, "No data to be displayed yet.")
+			return nil
+		}
+
+		return writeListTable(w, columns, rows)
+	case listFormatJSON:
+		return writeListJSON(w, columns, rows)
+	case listFormatCSV:
+		return writeListCSV(w, columns, rows)
+	case listFormatMarkdown:
+		return writeListMarkdown(w, columns, rows)
+	default:
+		return fmt.Errorf("unknown format %q (options: table / json / csv / markdown)", format)
+	}
+}
+
+func listCells(columns []*listColumn, row *listRow) []string {
+	cells := make([]string, 0, len(columns))
+	for _, c := range columns {
+		cells = append(cells, c.value(row))
+	}
+
+	return cells
+}
+
+func listHeaders(columns []*listColumn) []string {
+	headers := make([]string, 0, len(columns))
+	for _, c := range columns {
+		headers = append(headers, c.header)
+	}
+
+	return headers
+}
+
+func writeListTable(w io.Writer, columns []*listColumn, rows []*listRow) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 1, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(listHeaders(columns), "\t"))
+
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(listCells(columns, row), "\t"))
+	}
+
+	return tw.Flush()
+}
+
+// writeListJSON writes the selected columns as objects keyed by column name.
+func writeListJSON(w io.Writer, columns []*listColumn, rows []*listRow) error {
+	out := make([]map[string]string, 0, len(rows))
+
+	for _, row := range rows {
+		cells := listCells(columns, row)
+
+		obj := make(map[string]string, len(columns))
+		for i, c := range columns {
+			obj[c.name] = cells[i]
+		}
+
+		out = append(out, obj)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(out)
+}
+
+func writeListCSV(w io.Writer, columns []*listColumn, rows []*listRow) error {
+	cw := csv.NewWriter(w)
+
+	err := cw.Write(listHeaders(columns))
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		err = cw.Write(listCells(columns, row))
+		if err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
+
+func writeListMarkdown(w io.Writer, columns []*listColumn, rows []*listRow) error {
+	escape := strings.NewReplacer("|", "\\|", "\n", " ")
+
+	line := func(cells []string) {
+		for i, c := range cells {
+			cells[i] = escape.Replace(c)
+		}
+
+		fmt.Fprintf(w, "| %s |\n", strings.Join(cells, " | "))
+	}
+
+	line(listHeaders(columns))
+
+	separators := make([]string, len(columns))
+	for i := range separators {
+		separators[i] = "---"
+	}
+
+	line(separators)
+
+	for _, row := range rows {
+		line(listCells(columns, row))
+	}
+
+	return nil
+}
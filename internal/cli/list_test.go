@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTimeFlag(t *testing.T) {
+	now := time.Date(2025, 6, 15, 12, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		input   string
+		want    time.Time
+		wantErr bool
+	}{
+		{input: "", want: time.Time{}},
+		{input: "2025-06-01T08:30:00Z", want: time.Date(2025, 6, 1, 8, 30, 0, 0, time.UTC)},
+		{input: "2025-06-01 08:30:00", want: time.Date(2025, 6, 1, 8, 30, 0, 0, time.Local)},
+		{input: "2025-06-01", want: time.Date(2025, 6, 1, 0, 0, 0, 0, time.Local)},
+		{input: "7d", want: now.Add(-7 * 24 * time.Hour)},
+		{input: "1.5d", want: now.Add(-36 * time.Hour)},
+		{input: "12h", want: now.Add(-12 * time.Hour)},
+		{input: "90m", want: now.Add(-90 * time.Minute)},
+		{input: "yesterday", wantErr: true},
+		{input: "d", wantErr: true},
+		{input: "2025-13-01", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseTimeFlag(tt.input, now)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %s, want an error", got)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !got.Equal(tt.want) {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	rows := make([]*listRow, 5)
+	for i := range rows {
+		rows[i] = &listRow{}
+	}
+
+	tests := []struct {
+		limit, page, want int
+	}{
+		{limit: 0, page: 1, want: 5},
+		{limit: 2, page: 1, want: 2},
+		{limit: 2, page: 3, want: 1},
+		{limit: 2, page: 4, want: 0},
+	}
+
+	for _, tt := range tests {
+		got := paginate(rows, tt.limit, tt.page)
+		if len(got) != tt.want {
+			t.Errorf("paginate(limit %d, page %d) = %d rows, want %d", tt.limit, tt.page, len(got), tt.want)
+		}
+	}
+}
+
+func TestLinkBase(t *testing.T) {
+	tests := []struct {
+		link     string
+		want     string
+		warnings int
+	}{
+		{link: "http://minio:9000/minls-public/a.txt", want: "a.txt"},
+		{link: "http://minio:9000/minls-private/a.txt?X-Amz-Expires=3600", want: "a.txt"},
+		{link: "http://minio:9000/minls-public/dir%5Ca.txt", want: `dir\a.txt`},
+		{link: "http://minio:9000/minls-public/%zz", want: "?", warnings: 1},
+	}
+
+	for _, tt := range tests {
+		r := newListRow(nil)
+
+		if got := linkBase(r, tt.link); got != tt.want || len(r.warnings) != tt.warnings {
+			t.Errorf("linkBase(%q) = %q with %d warnings, want %q with %d", tt.link, got, len(r.warnings), tt.want, tt.warnings)
+		}
+	}
+}
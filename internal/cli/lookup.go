@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"os/signal"
+	"text/tabwriter"
+	"time"
+
+	"github.com/devusSs/minls/internal/log"
+	"github.com/devusSs/minls/internal/minio"
+	"github.com/devusSs/minls/internal/storage"
+)
+
+func Lookup() error {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	err := initialize()
+	if err != nil {
+		return fmt.Errorf("could not initialize: %w", err)
+	}
+
+	log.Debug("cli - Lookup", slog.String("action", "initialized"))
+
+	if len(os.Args) != lookupNeededArgs {
+		return errors.New("missing short url or keyword")
+	}
+
+	input := os.Args[2]
+
+	env, err := loadEnv()
+	if err != nil {
+		return fmt.Errorf("could not load env: %w", err)
+	}
+
+	sc, err := newShortener(env)
+	if err != nil {
+		return fmt.Errorf("could not create shortener: %w", err)
+	}
+
+	longURL, err := sc.Expand(ctx, input)
+	if err != nil {
+		return fmt.Errorf("could not expand %s: %w", input, err)
+	}
+
+	log.Debug("cli - Lookup", slog.String("action", "expanded"), slog.String("long_url", longURL))
+
+	data, err := storage.ReadData()
+	if err != nil {
+		return fmt.Errorf("could not read data from storage: %w", err)
+	}
+
+	entry := findEntryByMinioLink(data.Entries, longURL)
+	if entry == nil {
+		fmt.Printf("%s points to %s which is not in the upload history.\n", input, longURL)
+		return nil
+	}
+
+	// the entry may belong to another profile than the shortener we asked
+	if entryProfile(entry) != activeProfile {
+		env, err = entryEnv(entry)
+		if err != nil {
+			return fmt.Errorf("could not load env of entry: %w", err)
+		}
+	}
+
+	mc, err := newMinioClient(env)
+	if err != nil {
+		return fmt.Errorf("could not create minio client: %w", err)
+	}
+
+	return printLookup(ctx, mc, entry)
+}
+
+const lookupNeededArgs = 3
+
+// findEntryByMinioLink returns the entry whose minio link matches link.
+// Links are compared without their query (e.g. presigned signatures)
+// if no exact match exists.
+func findEntryByMinioLink(entries []*storage.DataEntry, link string) *storage.DataEntry {
+	for _, entry := range entries {
+		if entry.MinioLink == link {
+			return entry
+		}
+	}
+
+	stripped := stripQuery(link)
+	for _, entry := range entries {
+		if stripQuery(entry.MinioLink) == stripped {
+			return entry
+		}
+	}
+
+	return nil
+}
+
+func stripQuery(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return link
+	}
+
+	u.RawQuery = ""
+	u.Fragment = ""
+
+	return u.String()
+}
+
+func printLookup(ctx context.Context, mc *minio.Client, entry *storage.DataEntry) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	fmt.Fprintf(w, "ID:\t%d\n", entry.ID)
+	fmt.Fprintf(w, "Timestamp:\t%s\n", entry.Timestamp.Format(time.DateTime))
+	fmt.Fprintf(w, "Short link:\t%s\n", entry.YOURLSLink)
+	fmt.Fprintf(w, "Minio link:\t%s\n", entry.MinioLink)
+
+	loc, err := mc.ParseLink(entry.MinioLink)
+	if err != nil {
+		fmt.Fprintf(w, "Location:\tunknown (%v)\n", err)
+		return w.Flush()
+	}
+
+	fmt.Fprintf(w, "Bucket:\t%s\n", loc.Bucket)
+	fmt.Fprintf(w, "Key:\t%s\n", loc.Key)
+
+	info, err := mc.StatObject(ctx, loc.Bucket, loc.Key)
+	if err != nil {
+		log.Warn("cli - printLookup", slog.String("warn", "could not stat object"), slog.Any("err", err))
+		fmt.Fprintf(w, "Size:\tunknown (%v)\n", err)
+	} else {
+		fmt.Fprintf(w, "Size:\t%d bytes\n", info.Size)
+	}
+
+	fmt.Fprintf(w, "Expiry:\t%s\n", describeExpiry(entry.MinioLink))
+
+	return w.Flush()
+}
+
+// describeExpiry describes whether a presigned link has expired.
+func describeExpiry(link string) string {
+	expiry, presigned, err := storage.PresignedExpiry(link)
+	switch {
+	case err != nil:
+		return fmt.Sprintf("unknown (%v)", err)
+	case !presigned:
+		return "never (public link)"
+	case time.Now().After(expiry):
+		return fmt.Sprintf("expired at %s", expiry.Local().Format(time.DateTime))
+	default:
+		return fmt.Sprintf(
+			"expires at %s (in %s)",
+			expiry.Local().Format(time.DateTime),
+			time.Until(expiry).Round(time.Second),
+		)
+	}
+}
@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"github.com/devusSs/minls/internal/env"
+	"github.com/devusSs/minls/internal/minio"
+)
+
+func newMinioClient(e *env.Env) (*minio.Client, error) {
+	return minio.NewClient(&minio.Config{
+		Endpoint:           e.MinioEndpoint,
+		AccessKey:          e.MinioAccessKey,
+		AccessSecret:       e.MinioAccessSecret,
+		CABundle:           e.MinioCABundle,
+		ClientCert:         e.MinioClientCert,
+		ClientKey:          e.MinioClientKey,
+		InsecureSkipVerify: e.MinioInsecureSkipVerify,
+		BucketLookup:       e.MinioBucketLookup,
+		PublicBucket:       e.MinioBucketPublic,
+		PrivateBucket:      e.MinioBucketPrivate,
+		LinkExpiry:         e.MinioLinkExpiry,
+		Credentials: &minio.CredentialsConfig{
+			Source:             e.MinioCredentialsSource,
+			MCConfigFile:       e.MinioMCConfigFile,
+			MCAlias:            e.MinioMCAlias,
+			AWSCredentialsFile: e.MinioAWSCredentialsFile,
+			AWSProfile:         e.MinioAWSProfile,
+			STSEndpoint:        e.MinioSTSEndpoint,
+			STSRoleARN:         e.MinioSTSRoleARN,
+			STSDuration:        e.MinioSTSDuration,
+			Getenv:             e.Getenv,
+		},
+	})
+}
@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+
+	"github.com/devusSs/minls/internal/config"
+	"github.com/devusSs/minls/internal/env"
+	"github.com/devusSs/minls/internal/log"
+	"github.com/devusSs/minls/internal/minio"
+	"github.com/devusSs/minls/internal/shortener"
+	"github.com/devusSs/minls/internal/storage"
+)
+
+// activeProfile is the profile selected for this run, set by initialize.
+var activeProfile = config.DefaultProfile
+
+// activeConfig is the config of activeProfile, set by initialize.
+var activeConfig *config.Config
+
+// loadEnv loads the env of the active profile from its config,
+// which also holds the secrets config.Apply does not export.
+func loadEnv() (*env.Env, error) {
+	if activeConfig == nil {
+		return nil, errors.New("config not loaded")
+	}
+
+	return env.LoadWith(activeConfig.Getenv)
+}
+
+// entryProfile returns the profile the entry was uploaded with,
+// entries from before profiles existed belong to the default profile.
+func entryProfile(entry *storage.DataEntry) string {
+	return cmp.Or(entry.Profile, config.DefaultProfile)
+}
+
+// profileEnv loads the env of the profile.
+func profileEnv(profile string) (*env.Env, error) {
+	if profile == activeProfile {
+		return loadEnv()
+	}
+
+	cfg, err := config.LoadProfile(profile)
+	if err != nil {
+		return nil, fmt.Errorf("could not load profile %s: %w", profile, err)
+	}
+
+	addLogSecrets(cfg)
+
+	return env.LoadWith(cfg.Getenv)
+}
+
+// addLogSecrets masks the secret values of the config in all log output.
+func addLogSecrets(cfg *config.Config) {
+	for _, v := range cfg.Values {
+		if v.Key.Secret {
+			log.AddSecret(v.Value)
+		}
+	}
+}
+
+// entryEnv loads the env of the profile the entry was uploaded with,
+// so commands talk to the backend the entry lives on.
+func entryEnv(entry *storage.DataEntry) (*env.Env, error) {
+	return profileEnv(entryProfile(entry))
+}
+
+// entryObject creates a minio client for the profile of the entry
+// and returns the location of the entry's object.
+func entryObject(entry *storage.DataEntry) (*minio.Client, *minio.ObjectLocation, error) {
+	e, err := entryEnv(entry)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not load env: %w", err)
+	}
+
+	mc, err := newMinioClient(e)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not create minio client: %w", err)
+	}
+
+	loc, err := entryLocation(mc, entry)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not find object of entry %d: %w", entry.ID, err)
+	}
+
+	return mc, loc, nil
+}
+
+// entryShortener creates the shortener of the entry's profile,
+// it fails if the entry was shortened with another shortener.
+func entryShortener(entry *storage.DataEntry) (shortener.Shortener, error) {
+	e, err := entryEnv(entry)
+	if err != nil {
+		return nil, fmt.Errorf("could not load env: %w", err)
+	}
+
+	sc, err := newShortener(e)
+	if err != nil {
+		return nil, fmt.Errorf("could not create shortener: %w", err)
+	}
+
+	if entry.Shortener != "" && entry.Shortener != sc.Name() {
+		return nil, fmt.Errorf(
+			"entry %d was shortened with %s but profile %s uses %s",
+			entry.ID,
+			entry.Shortener,
+			entryProfile(entry),
+			sc.Name(),
+		)
+	}
+
+	return sc, nil
+}
+
+// shortenerCache creates one shortener per profile for commands
+// working on entries of multiple profiles.
+type shortenerCache struct {
+	shorteners map[string]shortener.Shortener
+	errs       map[string]error
+}
+
+func newShortenerCache() *shortenerCache {
+	return &shortenerCache{
+		shorteners: make(map[string]shortener.Shortener),
+		errs:       make(map[string]error),
+	}
+}
+
+// forEntry returns the shortener of the entry's profile.
+func (c *shortenerCache) forEntry(entry *storage.DataEntry) (shortener.Shortener, error) {
+	profile := entryProfile(entry)
+
+	if sc, ok := c.shorteners[profile]; ok {
+		return sc, nil
+	}
+
+	if err, ok := c.errs[profile]; ok {
+		return nil, err
+	}
+
+	e, err := profileEnv(profile)
+	if err != nil {
+		c.errs[profile] = err
+		return nil, err
+	}
+
+	sc, err := newShortener(e)
+	if err != nil {
+		c.errs[profile] = fmt.Errorf("could not create shortener of profile %s: %w", profile, err)
+		return nil, c.errs[profile]
+	}
+
+	c.shorteners[profile] = sc
+
+	return sc, nil
+}
@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/devusSs/minls/internal/secrets"
+)
+
+// prompter asks questions on stdout and reads the answers from stdin,
+// answers can be piped for scripted setups.
+type prompter struct {
+	r *bufio.Reader
+}
+
+func newPrompter() *prompter {
+	return &prompter{r: bufio.NewReader(os.Stdin)}
+}
+
+// ask returns the answer or def if the answer is empty.
+func (p *prompter) ask(label string, def string) (string, error) {
+	if def != "" {
+		fmt.Printf("%s [%s]: ", label, def)
+	} else {
+		fmt.Printf("%s: ", label)
+	}
+
+	line, err := p.readLine()
+	if err != nil {
+		return "", err
+	}
+
+	if line == "" {
+		return def, nil
+	}
+
+	return line, nil
+}
+
+// askSecret reads the answer without echo on terminals,
+// an empty answer keeps current.
+func (p *prompter) askSecret(label string, current string) (string, error) {
+	if current != "" {
+		label += " [keep current]"
+	}
+
+	var line string
+	var err error
+
+	if secrets.IsTerminal() {
+		line, err = secrets.ReadHidden(label + ": ")
+	} else {
+		fmt.Printf("%s: ", label)
+		line, err = p.readLine()
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	if line == "" {
+		return current, nil
+	}
+
+	return line, nil
+}
+
+// choose asks until the answer is one of options, def is used for empty answers.
+func (p *prompter) choose(label string, def string, options []string) (string, error) {
+	label = fmt.Sprintf("%s (%s)", label, strings.Join(options, " / "))
+
+	for {
+		v, err := p.ask(label, def)
+		if err != nil {
+			return "", err
+		}
+
+		if slices.Contains(options, v) {
+			return v, nil
+		}
+
+		fmt.Printf("Please answer one of: %s\n", strings.Join(options, ", "))
+	}
+}
+
+// confirm asks a yes / no question, def is used for empty answers.
+func (p *prompter) confirm(label string, def bool) (bool, error) {
+	options := "y/N"
+	if def {
+		options = "Y/n"
+	}
+
+	fmt.Printf("%s [%s]: ", label, options)
+
+	line, err := p.readLine()
+	if err != nil {
+		return false, err
+	}
+
+	switch strings.ToLower(line) {
+	case "":
+		return def, nil
+	case "y", "yes":
+		return true, nil
+	case "n", "no":
+		return false, nil
+	default:
+		return false, fmt.Errorf("invalid answer %q", line)
+	}
+}
+
+func (p *prompter) readLine() (string, error) {
+	line, err := p.r.ReadString('\n')
+	if errors.Is(err, io.EOF) && line != "" {
+		err = nil
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("could not read answer: %w", err)
+	}
+
+	return strings.TrimSpace(line), nil
+}
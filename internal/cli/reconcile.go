@@ -0,0 +1,227 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+
+	"github.com/devusSs/minls/internal/log"
+	"github.com/devusSs/minls/internal/minio"
+	"github.com/devusSs/minls/internal/shortener"
+	"github.com/devusSs/minls/internal/storage"
+)
+
+// Reconcile syncs the history with the objects stored in MinIO.
+// Tags and notes of known entries are taken from the objects
+// and objects missing from the history (e.g. after clear data) are added again.
+func Reconcile() error {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	err := initialize()
+	if err != nil {
+		return fmt.Errorf("could not initialize: %w", err)
+	}
+
+	log.Debug("cli - Reconcile", slog.String("action", "initialized"))
+
+	fs := flag.NewFlagSet("reconcile", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "only print what would change")
+
+	positional, err := parseFlags(fs, os.Args[2:])
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 0 {
+		return fmt.Errorf("unexpected args: %v", positional)
+	}
+
+	return reconcile(ctx, *dryRun)
+}
+
+// reconcile is shared by the reconcile command and data import --from-minio.
+func reconcile(ctx context.Context, dryRun bool) error {
+	e, err := loadEnv()
+	if err != nil {
+		return fmt.Errorf("could not load env: %w", err)
+	}
+
+	mc, err := newMinioClient(e)
+	if err != nil {
+		return fmt.Errorf("could not create minio client: %w", err)
+	}
+
+	data, err := storage.ReadData()
+	if err != nil {
+		return fmt.Errorf("could not read data from storage: %w", err)
+	}
+
+	// only entries of the active profile live in its buckets
+	known := make(map[minio.ObjectLocation]*storage.DataEntry, len(data.Entries))
+	for _, entry := range data.Entries {
+		if entryProfile(entry) != activeProfile {
+			continue
+		}
+
+		loc, locErr := entryLocation(mc, entry)
+		if locErr != nil {
+			log.Warn("cli - reconcile", slog.Int("id", entry.ID), slog.Any("err", locErr))
+			continue
+		}
+
+		known[*loc] = entry
+	}
+
+	var updated, recovered int
+
+	for _, bucket := range []string{mc.Bucket(true), mc.Bucket(false)} {
+		keys, listErr := mc.ListObjects(ctx, bucket)
+		if listErr != nil {
+			return fmt.Errorf("could not list objects in %s: %w", bucket, listErr)
+		}
+
+		for _, key := range keys {
+			if minio.IsReserved(key) {
+				continue
+			}
+
+			loc := minio.ObjectLocation{Bucket: bucket, Key: key}
+
+			changed, recErr := reconcileObject(ctx, mc, loc, known[loc], dryRun)
+			if recErr != nil {
+				return fmt.Errorf("could not reconcile %s/%s: %w", bucket, key, recErr)
+			}
+
+			switch {
+			case changed && known[loc] == nil:
+				recovered++
+			case changed:
+				updated++
+			}
+
+			delete(known, loc)
+		}
+	}
+
+	for loc, entry := range known {
+		fmt.Printf("missing: entry %d (%s/%s) has no object in minio\n", entry.ID, loc.Bucket, loc.Key)
+	}
+
+	verb := "Reconciled"
+	if dryRun {
+		verb = "Would reconcile"
+	}
+
+	fmt.Printf(
+		"%s: %d updated, %d recovered, %d missing in minio\n",
+		verb,
+		updated,
+		recovered,
+		len(known),
+	)
+
+	return nil
+}
+
+// reconcileObject updates the entry of the object or recovers it if entry is nil.
+// It reports whether anything changed (or would change in a dry run).
+func reconcileObject(
+	ctx context.Context,
+	mc *minio.Client,
+	loc minio.ObjectLocation,
+	entry *storage.DataEntry,
+	dryRun bool,
+) (bool, error) {
+	info, err := mc.StatObject(ctx, loc.Bucket, loc.Key)
+	if err != nil {
+		return false, err
+	}
+
+	tags, err := mc.ObjectTags(ctx, loc.Bucket, loc.Key)
+	if err != nil {
+		return false, err
+	}
+
+	if entry == nil {
+		return recoverEntry(ctx, mc, info, tags, dryRun)
+	}
+
+	note := entry.Note
+	if note == "" {
+		note = info.Note()
+	}
+
+	if slices.Equal(normalizeTags(entry.Tags), tags) && note == entry.Note {
+		return false, nil
+	}
+
+	fmt.Printf("update: entry %d tags [%s] note %q\n", entry.ID, strings.Join(tags, ", "), note)
+
+	if dryRun {
+		return true, nil
+	}
+
+	err = storage.UpdateEntry(entry.ID, func(entry *storage.DataEntry) error {
+		entry.Tags = tags
+		entry.Note = note
+		return nil
+	})
+
+	return err == nil, err
+}
+
+// recoverEntry writes a new entry for an object missing from the history.
+// The short link cannot be recovered, the entry uses the minio link instead.
+func recoverEntry(
+	ctx context.Context,
+	mc *minio.Client,
+	info *minio.ObjectInfo,
+	tags []string,
+	dryRun bool,
+) (bool, error) {
+	policy := "private"
+	if info.Bucket == mc.Bucket(true) {
+		policy = "public"
+	}
+
+	fmt.Printf("recover: %s/%s (%s)\n", info.Bucket, info.Key, info.OriginalName())
+
+	if dryRun {
+		return true, nil
+	}
+
+	link, err := mc.ObjectLink(ctx, info.Bucket, info.Key, policy == "public")
+	if err != nil {
+		return false, err
+	}
+
+	expiresAt, _, err := storage.PresignedExpiry(link)
+	if err != nil {
+		return false, err
+	}
+
+	err = storage.WriteEntry(&storage.DataEntry{
+		Timestamp:    info.LastModified,
+		MinioLink:    link,
+		YOURLSLink:   link,
+		Shortener:    shortener.NameNone,
+		Policy:       policy,
+		Bucket:       info.Bucket,
+		ObjectKey:    info.Key,
+		OriginalName: info.OriginalName(),
+		ContentType:  info.ContentType,
+		Size:         info.Size,
+		ExpiresAt:    expiresAt,
+		Tags:         tags,
+		Note:         info.Note(),
+		Profile:      activeProfile,
+	})
+
+	return err == nil, err
+}
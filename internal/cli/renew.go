@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"os/signal"
+	"path"
+	"time"
+
+	"github.com/devusSs/minls/internal/clip"
+	"github.com/devusSs/minls/internal/log"
+	"github.com/devusSs/minls/internal/shortener"
+	"github.com/devusSs/minls/internal/storage"
+)
+
+// Renew creates a new presigned link for a private entry using the minio
+// of the entry's profile and points the short link at it.
+//
+// Shorteners cannot change the target of a short link, so the short link
+// is deleted and created again with the same keyword.
+func Renew() error {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	err := initialize()
+	if err != nil {
+		return fmt.Errorf("could not initialize: %w", err)
+	}
+
+	log.Debug("cli - Renew", slog.String("action", "initialized"))
+
+	const renewArgs = 3
+	if len(os.Args) != renewArgs {
+		return errors.New("usage: minls renew <id>")
+	}
+
+	entry, err := readEntryArg(os.Args[2])
+	if err != nil {
+		return err
+	}
+
+	if entry.Policy == "public" {
+		return fmt.Errorf("entry %d is public, its link does not expire", entry.ID)
+	}
+
+	mc, loc, err := entryObject(entry)
+	if err != nil {
+		return err
+	}
+
+	link, err := mc.ObjectLink(ctx, loc.Bucket, loc.Key, false)
+	if err != nil {
+		return fmt.Errorf("could not create link for entry %d: %w", entry.ID, err)
+	}
+
+	short := link
+	keyword := entry.Keyword
+
+	if hasShortLink(entry) {
+		short, keyword, err = reshorten(ctx, entry, link)
+		if err != nil {
+			return err
+		}
+	}
+
+	expiresAt, _, err := storage.PresignedExpiry(link)
+	if err != nil {
+		return fmt.Errorf("could not parse expiry of new link: %w", err)
+	}
+
+	err = storage.UpdateEntry(entry.ID, func(e *storage.DataEntry) error {
+		e.MinioLink = link
+		e.YOURLSLink = short
+		e.Keyword = keyword
+		e.ExpiresAt = expiresAt
+		e.Archived = false
+		e.ArchivedAt = time.Time{}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("could not update entry %d: %w", entry.ID, err)
+	}
+
+	fmt.Printf("Renewed entry %d until %s: %s\n", entry.ID, expiresAt.Local().Format(time.DateTime), short)
+
+	err = clip.Write(short)
+	if err != nil {
+		log.Warn("cli - Renew", slog.String("warn", "could not write to clipboard"), slog.Any("err", err))
+	}
+
+	return nil
+}
+
+// reshorten replaces the short link of the entry with one pointing at link
+// and returns it with its keyword.
+func reshorten(ctx context.Context, entry *storage.DataEntry, link string) (string, string, error) {
+	sc, err := entryShortener(entry)
+	if err != nil {
+		return "", "", err
+	}
+
+	keyword := entry.Keyword
+
+	err = sc.Delete(ctx, entry.YOURLSLink)
+	switch {
+	case err == nil, errors.Is(err, shortener.ErrNotFound):
+	case errors.Is(err, shortener.ErrNotSupported):
+		// the old keyword stays taken, the new link gets a fresh one
+		fmt.Fprintf(os.Stderr, "WARNING: %s cannot delete links, the short link changes\n", sc.Name())
+		keyword = ""
+	default:
+		return "", "", fmt.Errorf("could not delete old short link of entry %d: %w", entry.ID, err)
+	}
+
+	short, err := sc.Shorten(ctx, link, &shortener.ShortenOptions{Keyword: keyword, Title: entry.Title})
+	if err != nil {
+		return "", "", fmt.Errorf("could not shorten new link of entry %d: %w", entry.ID, err)
+	}
+
+	if keyword == "" {
+		u, parseErr := url.Parse(short)
+		if parseErr == nil {
+			keyword = path.Base(u.Path)
+		}
+	}
+
+	return short, keyword, nil
+}
@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/devusSs/minls/internal/log"
+	"github.com/devusSs/minls/internal/search"
+	"github.com/devusSs/minls/internal/storage"
+)
+
+func Search() error {
+	err := initialize()
+	if err != nil {
+		return fmt.Errorf("could not initialize: %w", err)
+	}
+
+	log.Debug("cli - Search", slog.String("action", "initialized"))
+
+	fs := flag.NewFlagSet("search", flag.ContinueOnError)
+	all := fs.Bool("all", false, "also search archived entries")
+	noContent := fs.Bool("no-content", false, "do not search indexed file content")
+	limit := fs.Int("limit", 20, "maximum number of results, 0 shows all")
+	asJSON := fs.Bool("json", false, "print the results as JSON")
+
+	positional, err := parseFlags(fs, os.Args[2:])
+	if err != nil {
+		return err
+	}
+
+	query := strings.Join(positional, " ")
+	if strings.TrimSpace(query) == "" {
+		return errors.New("missing query")
+	}
+
+	if *limit < 0 {
+		return errors.New("--limit must not be negative")
+	}
+
+	data, err := storage.ReadData()
+	if err != nil {
+		return fmt.Errorf("could not read data from storage: %w", err)
+	}
+
+	content := make(map[int]string)
+	if !*noContent {
+		content, err = storage.ReadIndexedContent()
+		if err != nil {
+			return fmt.Errorf("could not read content index: %w", err)
+		}
+	}
+
+	entries := make(map[int]*storage.DataEntry, len(data.Entries))
+	docs := make([]search.Document, 0, len(data.Entries))
+
+	for _, entry := range data.Entries {
+		if entry.Archived && !*all {
+			continue
+		}
+
+		entries[entry.ID] = entry
+		docs = append(docs, searchDocument(entry, content[entry.ID]))
+	}
+
+	results := search.Search(query, docs)
+
+	log.Debug(
+		"cli - Search",
+		slog.String("query", query),
+		slog.Int("documents", len(docs)),
+		slog.Int("results", len(results)),
+	)
+
+	if *limit > 0 && len(results) > *limit {
+		results = results[:*limit]
+	}
+
+	if *asJSON {
+		return writeSearchJSON(results, entries)
+	}
+
+	return writeSearchTable(results, entries)
+}
+
+// searchDocument describes which entry fields are searched and how they rank.
+func searchDocument(entry *storage.DataEntry, content string) search.Document {
+	return search.Document{
+		ID: entry.ID,
+		Fields: []search.Field{
+			{Name: "name", Text: entry.OriginalName, Weight: 4},
+			{Name: "title", Text: entry.Title, Weight: 4},
+			{Name: "tags", Text: strings.Join(entry.Tags, " "), Weight: 4},
+			{Name: "note", Text: entry.Note, Weight: 3},
+			{Name: "keyword", Text: entry.Keyword, Weight: 3},
+			{Name: "type", Text: entry.ContentType, Weight: 2},
+			{Name: "content", Text: content, Weight: 1, Long: true},
+		},
+	}
+}
+
+type searchResult struct {
+	Score   int                `json:"score"`
+	Matched []string           `json:"matched"`
+	Snippet string             `json:"snippet,omitempty"`
+	Entry   *storage.DataEntry `json:"entry"`
+}
+
+func writeSearchJSON(results []search.Result, entries map[int]*storage.DataEntry) error {
+	out := make([]*searchResult, 0, len(results))
+	for _, r := range results {
+		out = append(out, &searchResult{
+			Score:   r.Score,
+			Matched: r.Fields,
+			Snippet: r.Snippet,
+			Entry:   entries[r.ID],
+		})
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+func writeSearchTable(results []search.Result, entries map[int]*storage.DataEntry) error {
+	if len(results) == 0 {
+		fmt.Println("No matching uploads found.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tScore\tTimestamp\tOriginal name\tShort link\tMatched")
+
+	for _, r := range results {
+		entry := entries[r.ID]
+		fmt.Fprintf(
+			w,
+			"%d\t%d\t%s\t%s\t%s\t%s\n",
+			entry.ID,
+			r.Score,
+			entry.Timestamp.Format(time.DateTime),
+			entry.OriginalName,
+			entry.YOURLSLink,
+			strings.Join(r.Fields, ", "),
+		)
+
+		if r.Snippet != "" {
+			fmt.Fprintf(w, "\t\t\t\t\t%s\n", r.Snippet)
+		}
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/devusSs/minls/internal/env"
+	"github.com/devusSs/minls/internal/log"
+	"github.com/devusSs/minls/internal/shlink"
+	"github.com/devusSs/minls/internal/shortener"
+	"github.com/devusSs/minls/internal/yourls"
+)
+
+func newShortener(e *env.Env) (shortener.Shortener, error) {
+	log.Debug("cli - newShortener", slog.String("shortener", e.Shortener))
+
+	switch e.Shortener {
+	case shortener.NameNone:
+		return shortener.NewNone(), nil
+	case shortener.NameYOURLS:
+		auth, err := newYOURLSAuth(e)
+		if err != nil {
+			return nil, fmt.Errorf("could not create yourls auth: %w", err)
+		}
+
+		return yourls.NewClient(e.YOURLSEndpoint, auth, &yourls.TransportConfig{
+			Timeout:          e.YOURLSTimeout,
+			MaxRetries:       e.YOURLSRetries,
+			ProxyURL:         e.YOURLSProxy,
+			CABundle:         e.YOURLSCABundle,
+			PinnedCertSHA256: e.YOURLSPinnedCert,
+		})
+	case shortener.NameShlink:
+		return shlink.NewClient(e.ShlinkEndpoint, e.ShlinkAPIKey), nil
+	default:
+		return nil, fmt.Errorf("unknown shortener: %s", e.Shortener)
+	}
+}
+
+func newYOURLSAuth(e *env.Env) (yourls.Auth, error) {
+	log.Debug("cli - newYOURLSAuth", slog.String("mode", e.YOURLSAuthMode))
+
+	switch e.YOURLSAuthMode {
+	case yourls.AuthModeSignature:
+		return &yourls.SignatureAuth{Signature: e.YOURLSSignature}, nil
+	case yourls.AuthModeTimedSignature:
+		_, ok := yourls.SignatureHashes[e.YOURLSHash]
+		if !ok {
+			return nil, fmt.Errorf("unsupported signature hash: %s", e.YOURLSHash)
+		}
+
+		return &yourls.TimedSignatureAuth{Signature: e.YOURLSSignature, Hash: e.YOURLSHash}, nil
+	case yourls.AuthModePassword:
+		return &yourls.PasswordAuth{Username: e.YOURLSUsername, Password: e.YOURLSPassword}, nil
+	default:
+		return nil, fmt.Errorf("unknown yourls auth mode: %s", e.YOURLSAuthMode)
+	}
+}
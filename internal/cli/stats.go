@@ -0,0 +1,224 @@
+package cli
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/devusSs/minls/internal/log"
+	"github.com/devusSs/minls/internal/shortener"
+	"github.com/devusSs/minls/internal/storage"
+)
+
+func Stats() error {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	err := initialize()
+	if err != nil {
+		return fmt.Errorf("could not initialize: %w", err)
+	}
+
+	log.Debug("cli - Stats", slog.String("action", "initialized"))
+
+	args, err := parseStatsArgs(os.Args[2:])
+	if err != nil {
+		return fmt.Errorf("could not parse stats args: %w", err)
+	}
+
+	log.Debug("cli - Stats", slog.String("action", "parsed_args"), slog.Any("args", args))
+
+	data, err := storage.ReadData()
+	if err != nil {
+		return fmt.Errorf("could not read data from storage: %w", err)
+	}
+
+	entries, err := filterStatsEntries(data.Entries, args.id)
+	if err != nil {
+		return err
+	}
+
+	report := collectStats(ctx, newShortenerCache(), entries, args.top)
+
+	log.Debug("cli - Stats", slog.String("action", "collected_stats"), slog.Any("report", report))
+
+	if args.json {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+
+	return printStatsReport(report)
+}
+
+type statsArgs struct {
+	id   int
+	top  int
+	json bool
+}
+
+const defaultStatsTop = 5
+
+func parseStatsArgs(args []string) (*statsArgs, error) {
+	sa := &statsArgs{}
+
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	fs.BoolVar(&sa.json, "json", false, "print the stats as JSON")
+	fs.IntVar(&sa.top, "top", defaultStatsTop, "number of top links to show")
+
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(positional) {
+	case 0:
+	case 1:
+		sa.id, err = strconv.Atoi(positional[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid id provided: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("too many args: %d", len(positional))
+	}
+
+	return sa, nil
+}
+
+// filterStatsEntries returns all entries or only the entry with the id
+// if the id is not 0.
+func filterStatsEntries(entries []*storage.DataEntry, id int) ([]*storage.DataEntry, error) {
+	if id == 0 {
+		return entries, nil
+	}
+
+	for _, entry := range entries {
+		if entry.ID == id {
+			return []*storage.DataEntry{entry}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no entry with id %d found", id)
+}
+
+type statsReport struct {
+	Links       []*linkStats `json:"links"`
+	TotalLinks  int          `json:"total_links"`
+	TotalClicks int64        `json:"total_clicks"`
+	Top         []*linkStats `json:"top"`
+}
+
+type linkStats struct {
+	ID       int    `json:"id"`
+	ShortURL string `json:"short_url"`
+	Clicks   int64  `json:"clicks"`
+	Error    string `json:"error,omitempty"`
+}
+
+func collectStats(
+	ctx context.Context,
+	shorteners *shortenerCache,
+	entries []*storage.DataEntry,
+	top int,
+) *statsReport {
+	report := &statsReport{Links: make([]*linkStats, 0, len(entries))}
+
+	for _, entry := range entries {
+		ls := &linkStats{ID: entry.ID, ShortURL: entry.YOURLSLink}
+
+		sc, err := shorteners.forEntry(entry)
+		if err != nil {
+			ls.Error = err.Error()
+			report.Links = append(report.Links, ls)
+			continue
+		}
+
+		if entry.Shortener != "" && entry.Shortener != sc.Name() {
+			ls.Error = fmt.Sprintf("created with shortener %s, using %s", entry.Shortener, sc.Name())
+			report.Links = append(report.Links, ls)
+			continue
+		}
+
+		s, err := sc.Stats(ctx, entry.YOURLSLink)
+		switch {
+		case errors.Is(err, shortener.ErrNotSupported):
+			ls.Error = "not supported by shortener"
+		case err != nil:
+			log.Warn(
+				"cli - collectStats",
+				slog.Int("id", entry.ID),
+				slog.String("warn", "could not get stats"),
+				slog.Any("err", err),
+			)
+			ls.Error = err.Error()
+		default:
+			ls.Clicks = s.Clicks
+			report.TotalClicks += s.Clicks
+		}
+
+		report.Links = append(report.Links, ls)
+	}
+
+	report.TotalLinks = len(report.Links)
+
+	report.Top = slices.Clone(report.Links)
+	slices.SortStableFunc(report.Top, func(a *linkStats, b *linkStats) int {
+		return cmp.Compare(b.Clicks, a.Clicks)
+	})
+
+	if len(report.Top) > top {
+		report.Top = report.Top[:max(top, 0)]
+	}
+
+	return report
+}
+
+func printStatsReport(report *statsReport) error {
+	if len(report.Links) == 0 {
+		fmt.Println("No data to be displayed yet.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	fmt.Fprintln(w, "ID\tShort link\tClicks")
+
+	for _, ls := range report.Links {
+		clicks := strconv.FormatInt(ls.Clicks, 10)
+		if ls.Error != "" {
+			clicks = "- (" + ls.Error + ")"
+		}
+
+		fmt.Fprintf(w, "%d\t%s\t%s\n", ls.ID, ls.ShortURL, clicks)
+	}
+
+	err := w.Flush()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	fmt.Printf("Total links:\t%d\n", report.TotalLinks)
+	fmt.Printf("Total clicks:\t%d\n", report.TotalClicks)
+
+	if len(report.Links) <= 1 {
+		return nil
+	}
+
+	fmt.Println()
+	fmt.Println("Top links:")
+
+	for i, ls := range report.Top {
+		fmt.Printf("%d. %s (%d clicks)\n", i+1, ls.ShortURL, ls.Clicks)
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/devusSs/minls/internal/log"
+	"github.com/devusSs/minls/internal/minio"
+	"github.com/devusSs/minls/internal/storage"
+)
+
+func Tag() error {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	err := initialize()
+	if err != nil {
+		return fmt.Errorf("could not initialize: %w", err)
+	}
+
+	log.Debug("cli - Tag", slog.String("action", "initialized"))
+
+	const tagMinArgs = 5
+	if len(os.Args) < tagMinArgs {
+		return errors.New("usage: minls tag <id> add / remove <tag>...")
+	}
+
+	id, err := strconv.Atoi(os.Args[2])
+	if err != nil {
+		return fmt.Errorf("invalid id provided: %w", err)
+	}
+
+	action := strings.ToLower(os.Args[3])
+	if action != "add" && action != "remove" {
+		return fmt.Errorf("unknown tag action %q (options: add / remove)", action)
+	}
+
+	changed := normalizeTags(os.Args[4:])
+
+	entry, err := storage.ReadEntry(id)
+	if err != nil {
+		return fmt.Errorf("could not read entry %d: %w", id, err)
+	}
+
+	tags := slices.Clone(entry.Tags)
+	if action == "add" {
+		tags = normalizeTags(append(tags, changed...))
+	} else {
+		tags = slices.DeleteFunc(tags, func(t string) bool { return slices.Contains(changed, t) })
+	}
+
+	err = minio.ValidateTags(tags)
+	if err != nil {
+		return err
+	}
+
+	e, err := entryEnv(entry)
+	if err != nil {
+		return fmt.Errorf("could not load env: %w", err)
+	}
+
+	mc, err := newMinioClient(e)
+	if err != nil {
+		return fmt.Errorf("could not create minio client: %w", err)
+	}
+
+	loc, err := entryLocation(mc, entry)
+	if err != nil {
+		return fmt.Errorf("could not find object of entry %d: %w", id, err)
+	}
+
+	// minio first, the history is only updated if the object tags were written
+	err = mc.SetObjectTags(ctx, loc.Bucket, loc.Key, tags)
+	if err != nil {
+		return fmt.Errorf("could not set object tags: %w", err)
+	}
+
+	err = storage.UpdateEntry(id, func(entry *storage.DataEntry) error {
+		entry.Tags = tags
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("could not update entry %d: %w", id, err)
+	}
+
+	log.Info("cli - Tag", slog.Int("id", id), slog.String("action", action), slog.Any("tags", tags))
+
+	fmt.Printf("Tags of entry %d: %s\n", id, strings.Join(tags, ", "))
+
+	return nil
+}
+
+// normalizeTags trims, deduplicates and sorts the tags.
+func normalizeTags(tags []string) []string {
+	out := make([]string, 0, len(tags))
+	for _, t := range tags {
+		t = strings.TrimSpace(t)
+		if t != "" {
+			out = append(out, t)
+		}
+	}
+
+	slices.Sort(out)
+
+	return slices.Compact(out)
+}
+
+// entryLocation returns where the object of the entry is stored,
+// entries written before the location was recorded fall back to the link.
+func entryLocation(mc *minio.Client, entry *storage.DataEntry) (*minio.ObjectLocation, error) {
+	if entry.Bucket != "" && entry.ObjectKey != "" {
+		return &minio.ObjectLocation{Bucket: entry.Bucket, Key: entry.ObjectKey}, nil
+	}
+
+	return mc.ParseLink(entry.MinioLink)
+}
@@ -2,18 +2,24 @@ package cli
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"os/signal"
+	"slices"
+	"strings"
 
 	"github.com/devusSs/minls/internal/clip"
 	"github.com/devusSs/minls/internal/env"
 	"github.com/devusSs/minls/internal/log"
 	"github.com/devusSs/minls/internal/minio"
+	"github.com/devusSs/minls/internal/shortener"
 	"github.com/devusSs/minls/internal/storage"
-	"github.com/devusSs/minls/internal/yourls"
 )
 
 func Upload() error {
@@ -27,34 +33,44 @@ func Upload() error {
 
 	log.Debug("cli - Upload", slog.String("action", "initialized"))
 
-	env, err := env.Load()
+	env, err := loadEnv()
 	if err != nil {
 		return fmt.Errorf("could not load env: %w", err)
 	}
 
 	log.Debug("cli - Upload", slog.String("action", "loaded_env"), slog.Any("env", env))
 
-	if len(os.Args) != neededArgs {
-		return fmt.Errorf("missing upload filepath or policy, len(os.Args) = %d", len(os.Args))
+	args, err := parseUploadArgs(os.Args[2:])
+	if err != nil {
+		return fmt.Errorf("could not parse upload args: %w", err)
 	}
 
-	log.Debug("cli - Upload", slog.String("action", "checked_args"), slog.Int("args", len(os.Args)))
+	log.Debug("cli - Upload", slog.String("action", "parsed_args"), slog.Any("args", args))
 
-	fp, err := getFilePath()
+	hash, err := hashFile(args.filePath)
 	if err != nil {
-		return fmt.Errorf("could not get file path: %w", err)
+		return fmt.Errorf("could not hash file: %w", err)
 	}
 
-	log.Debug("cli - Upload", slog.String("action", "got_file_path"), slog.String("fp", fp))
+	warnPreviousUploads(hash)
 
-	p, err := getPolicy()
+	sc, err := newShortener(env)
 	if err != nil {
-		return fmt.Errorf("could not get policy: %w", err)
+		return fmt.Errorf("could not create shortener: %w", err)
 	}
 
-	log.Debug("cli - Upload", slog.String("action", "got_policy"), slog.String("p", p))
+	log.Debug(
+		"cli - Upload",
+		slog.String("action", "shortener_init"),
+		slog.String("shortener", sc.Name()),
+	)
+
+	opts, err := resolveShortenOptions(ctx, sc, env, args)
+	if err != nil {
+		return fmt.Errorf("could not resolve shorten options: %w", err)
+	}
 
-	mc, err := minio.NewClient(env.MinioAccessKey, env.MinioAccessSecret, env.MinioEndpoint)
+	mc, err := newMinioClient(env)
 	if err != nil {
 		return fmt.Errorf("could not create minio client: %w", err)
 	}
@@ -64,39 +80,70 @@ func Upload() error {
 	log.Debug(
 		"cli - Upload",
 		slog.String("action", "uploading_to_minio"),
-		slog.String("fp", fp),
-		slog.String("p", p),
+		slog.String("fp", args.filePath),
+		slog.String("p", args.policy),
 	)
 
-	minioLink, err := mc.UploadFile(ctx, fp, p == "public")
+	ui, err := mc.UploadFile(ctx, args.filePath, args.policy == "public", &minio.UploadOptions{
+		Tags: args.tags,
+		Note: args.note,
+	})
 	if err != nil {
 		return fmt.Errorf("could not upload file: %w", err)
 	}
 
+	minioLink := ui.Link
+
 	log.Info(
 		"cli - Upload",
 		slog.String("action", "uploaded_to_minio"),
 		slog.String("minio_link", minioLink),
 	)
 
-	yc := yourls.NewClient(env.YOURLSEndpoint, env.YOURLSSignature)
-
-	log.Debug("cli - Upload", slog.String("action", "yourls_client_init"))
+	expiresAt, _, err := storage.PresignedExpiry(minioLink)
+	if err != nil {
+		removeUpload(ctx, mc, ui)
+		return fmt.Errorf("could not get link expiry: %w", err)
+	}
 
-	link, err := yc.Shorten(ctx, minioLink)
+	link, err := sc.Shorten(ctx, minioLink, opts)
 	if err != nil {
+		removeUpload(ctx, mc, ui)
 		return fmt.Errorf("could not shorten link: %w", err)
 	}
 
 	log.Info("cli - Upload", slog.String("action", "shortened_url"), slog.String("link", link))
 
-	err = storage.WriteEntry(&storage.DataEntry{MinioLink: minioLink, YOURLSLink: link})
+	entry := &storage.DataEntry{
+		MinioLink:    minioLink,
+		YOURLSLink:   link,
+		Shortener:    sc.Name(),
+		Keyword:      opts.Keyword,
+		Title:        opts.Title,
+		Hash:         hash,
+		ExpiresAt:    expiresAt,
+		Policy:       args.policy,
+		Bucket:       ui.Bucket,
+		ObjectKey:    ui.Key,
+		OriginalName: ui.OriginalName,
+		ContentType:  ui.ContentType,
+		Size:         ui.Size,
+		Tags:         args.tags,
+		Note:         args.note,
+		Profile:      activeProfile,
+	}
+
+	err = storage.WriteEntry(entry)
 	if err != nil {
 		return fmt.Errorf("could not write to storage: %w", err)
 	}
 
 	log.Info("cli - Upload", slog.String("action", "storage_write_entry"))
 
+	if env.SearchIndexContent || args.index {
+		indexContent(entry, args.filePath)
+	}
+
 	err = clip.Write(link)
 	if err != nil {
 		return fmt.Errorf("could not write to clipboard: %w", err)
@@ -107,10 +154,65 @@ func Upload() error {
 	return nil
 }
 
-const neededArgs = 4
+type uploadArgs struct {
+	filePath      string
+	policy        string
+	keyword       string
+	title         string
+	keywordSuffix bool
+	index         bool
+	tags          []string
+	note          string
+}
+
+const uploadNeededArgs = 2
+
+func parseUploadArgs(args []string) (*uploadArgs, error) {
+	ua := &uploadArgs{}
+
+	fs := flag.NewFlagSet("upload", flag.ContinueOnError)
+	fs.StringVar(&ua.keyword, "keyword", "", "custom keyword for the short link")
+	fs.StringVar(&ua.title, "title", "", "custom title for the short link")
+	fs.BoolVar(
+		&ua.keywordSuffix,
+		"keyword-suffix",
+		false,
+		"append a suffix to the keyword if it is already taken instead of failing",
+	)
+	fs.BoolVar(&ua.index, "index", false, "index the content of small text files for search")
+	fs.Var((*stringList)(&ua.tags), "tag", "tag the upload, can be passed multiple times")
+	fs.StringVar(&ua.note, "note", "", "note why the file was uploaded")
+
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(positional) != uploadNeededArgs {
+		return nil, fmt.Errorf("missing upload filepath or policy, got %d args", len(positional))
+	}
 
-func getFilePath() (string, error) {
-	fp := os.Args[2]
+	ua.filePath, err = getFilePath(positional[0])
+	if err != nil {
+		return nil, fmt.Errorf("could not get file path: %w", err)
+	}
+
+	ua.policy, err = getPolicy(positional[1])
+	if err != nil {
+		return nil, fmt.Errorf("could not get policy: %w", err)
+	}
+
+	ua.tags = normalizeTags(ua.tags)
+
+	err = minio.ValidateTags(ua.tags)
+	if err != nil {
+		return nil, err
+	}
+
+	return ua, nil
+}
+
+func getFilePath(fp string) (string, error) {
 	if fp == "" {
 		return "", errors.New("no file path provided")
 	}
@@ -125,8 +227,7 @@ func getFilePath() (string, error) {
 	return fp, nil
 }
 
-func getPolicy() (string, error) {
-	p := os.Args[3]
+func getPolicy(p string) (string, error) {
 	if p != "public" && p != "private" {
 		return "", fmt.Errorf("invalid policy provided: %s", p)
 	}
@@ -135,3 +236,168 @@ func getPolicy() (string, error) {
 
 	return p, nil
 }
+
+// removeUpload removes an uploaded object which did not get a short link,
+// failures only produce warnings since the upload already failed.
+func removeUpload(ctx context.Context, mc *minio.Client, ui *minio.UploadInfo) {
+	err := mc.RemoveObject(ctx, ui.Bucket, ui.Key)
+	if err != nil {
+		log.Warn(
+			"cli - removeUpload",
+			slog.String("warn", "could not remove orphaned object"),
+			slog.String("bucket", ui.Bucket),
+			slog.String("key", ui.Key),
+			slog.Any("err", err),
+		)
+		return
+	}
+
+	log.Debug("cli - removeUpload", slog.String("action", "removed"), slog.String("key", ui.Key))
+}
+
+// maxKeywordAttempts limits how often we try to find a free keyword.
+const maxKeywordAttempts = 10
+
+// resolveShortenOptions picks a free keyword for the short link.
+// Custom keywords fail if they are taken unless args.keywordSuffix is set,
+// generated keywords are simply regenerated.
+func resolveShortenOptions(
+	ctx context.Context,
+	sc shortener.Shortener,
+	e *env.Env,
+	args *uploadArgs,
+) (*shortener.ShortenOptions, error) {
+	opts := &shortener.ShortenOptions{Keyword: args.keyword, Title: args.title}
+
+	for attempt := range maxKeywordAttempts {
+		if args.keyword == "" {
+			kw, err := shortener.NewKeyword(e.KeywordStyle, e.KeywordLength)
+			if err != nil {
+				return nil, fmt.Errorf("could not generate keyword: %w", err)
+			}
+
+			opts.Keyword = kw
+		} else if attempt > 0 {
+			opts.Keyword = fmt.Sprintf("%s-%d", args.keyword, attempt+1)
+		}
+
+		_, err := sc.Expand(ctx, opts.Keyword)
+		if errors.Is(err, shortener.ErrNotFound) {
+			log.Debug(
+				"cli - resolveShortenOptions",
+				slog.String("action", "keyword_free"),
+				slog.String("keyword", opts.Keyword),
+			)
+			return opts, nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("could not check keyword %s: %w", opts.Keyword, err)
+		}
+
+		log.Debug(
+			"cli - resolveShortenOptions",
+			slog.String("action", "keyword_taken"),
+			slog.String("keyword", opts.Keyword),
+		)
+
+		if args.keyword != "" && !args.keywordSuffix {
+			return nil, fmt.Errorf(
+				"keyword %s is already taken, use --keyword-suffix to pick an alternative",
+				args.keyword,
+			)
+		}
+	}
+
+	return nil, fmt.Errorf("could not find a free keyword after %d attempts", maxKeywordAttempts)
+}
+
+func hashFile(fp string) (string, error) {
+	f, err := os.Open(fp)
+	if err != nil {
+		return "", fmt.Errorf("could not open file: %w", err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+
+	_, err = io.Copy(h, f)
+	if err != nil {
+		return "", fmt.Errorf("could not read file: %w", err)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// warnPreviousUploads warns if the file with the hash was uploaded before.
+func warnPreviousUploads(hash string) {
+	previous, err := storage.FindByHash(hash)
+	if err != nil {
+		log.Warn(
+			"cli - warnPreviousUploads",
+			slog.String("warn", "could not look up previous uploads"),
+			slog.Any("err", err),
+		)
+		return
+	}
+
+	for _, entry := range previous {
+		log.Warn(
+			"cli - warnPreviousUploads",
+			slog.String("warn", "file has been uploaded before"),
+			slog.Int("id", entry.ID),
+			slog.String("link", entry.YOURLSLink),
+		)
+	}
+}
+
+// indexContent indexes the content of small text uploads for search,
+// failures only produce warnings since the upload itself succeeded.
+func indexContent(entry *storage.DataEntry, fp string) {
+	if !isTextContentType(entry.ContentType) || entry.Size > storage.MaxIndexedContentSize {
+		log.Debug(
+			"cli - indexContent",
+			slog.String("action", "skip"),
+			slog.String("content_type", entry.ContentType),
+			slog.Int64("size", entry.Size),
+		)
+		return
+	}
+
+	content, err := os.ReadFile(fp)
+	if err == nil {
+		err = storage.IndexContent(entry.ID, content)
+	}
+
+	if err != nil {
+		log.Warn(
+			"cli - indexContent",
+			slog.String("warn", "could not index content"),
+			slog.Int("id", entry.ID),
+			slog.Any("err", err),
+		)
+		return
+	}
+
+	log.Debug("cli - indexContent", slog.String("action", "indexed"), slog.Int("id", entry.ID))
+}
+
+// textContentTypes are non text/* content types which hold plain text.
+var textContentTypes = []string{
+	"application/json",
+	"application/xml",
+	"application/javascript",
+	"application/x-sh",
+	"application/x-yaml",
+	"application/toml",
+}
+
+func isTextContentType(ct string) bool {
+	ct, _, _ = strings.Cut(ct, ";")
+	ct = strings.TrimSpace(ct)
+
+	return strings.HasPrefix(ct, "text/") ||
+		strings.HasSuffix(ct, "+json") ||
+		strings.HasSuffix(ct, "+xml") ||
+		slices.Contains(textContentTypes, ct)
+}
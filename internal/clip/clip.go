@@ -1,6 +1,7 @@
 package clip
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"runtime"
@@ -24,6 +25,26 @@ func Init() error {
 	return nil
 }
 
+// Check reports whether the clipboard can be used, on Linux
+// this needs xclip, xsel or wl-clipboard and a running display.
+func Check() error {
+	err := Init()
+	if err != nil {
+		return err
+	}
+
+	if clipboard.Unsupported {
+		return errors.New("no clipboard utility found (install xclip, xsel or wl-clipboard)")
+	}
+
+	_, err = clipboard.ReadAll()
+	if err != nil {
+		return fmt.Errorf("could not read clipboard: %w", err)
+	}
+
+	return nil
+}
+
 func Read() (string, error) {
 	log.Debug("clip - Read", slog.String("go_os", runtime.GOOS))
 	return clipboard.ReadAll()
@@ -0,0 +1,603 @@
+// Package config resolves the configuration from flags, the environment,
+// a .env file and TOML config files.
+//
+// Values can be grouped in named profiles (see readFile),
+// the active profile is selected by --profile, MINLS_PROFILE
+// or profiles.default in the config files.
+//
+// Precedence (highest first):
+//
+//	flags (--set key=value) > environment
+//	  > profile tables of the project config, then of the user config
+//	  > project config (.minls.toml in the working dir or a parent)
+//	  > .env file in the config dir > user config (config.toml in the config dir)
+//	  > secret store (keyring or encrypted file) > defaults
+//
+// The secret store is only asked for secrets the configuration needs
+// and which are not set in plaintext, see loadSecrets.
+//
+// The rest of minls reads its configuration from the environment,
+// Apply exports the resolved values so every layer is visible there.
+// Secrets are never exported, they are read with Config.Getenv.
+package config
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/joho/godotenv"
+
+	"github.com/devusSs/minls/internal/paths"
+	"github.com/devusSs/minls/internal/secrets"
+)
+
+// File names of the config files.
+const (
+	ProjectFileName = ".minls.toml"
+	UserFileName    = "config.toml"
+	DotEnvFileName  = ".env"
+)
+
+// Profiles select between multiple deployments.
+const (
+	EnvProfile     = "MINLS_PROFILE"
+	DefaultProfile = "default"
+	profilesTable  = "profiles"
+)
+
+// Sources of a value, file sources are followed by the file path.
+const (
+	SourceFlag    = "flag"
+	SourceEnv     = "env"
+	SourceSecrets = "secrets"
+	SourceDotEnv  = ".env"
+	SourceProject = "project"
+	SourceUser    = "user"
+	SourceDefault = "default"
+)
+
+// Value is the effective value of a key and where it came from.
+type Value struct {
+	Key    *Key   `json:"-"`
+	Name   string `json:"key"`
+	Env    string `json:"env"`
+	Value  string `json:"value"`
+	Source string `json:"source,omitempty"`
+	Path   string `json:"path,omitempty"`
+	// Profile is set if the value came from a profile section.
+	Profile string `json:"profile,omitempty"`
+	// Shadowed are the lower layers which set the key too (source or file path).
+	Shadowed []string `json:"shadowed,omitempty"`
+}
+
+// IsSet reports whether the value was configured or has a default.
+func (v *Value) IsSet() bool {
+	return v.Source != ""
+}
+
+// Masked returns the value with secrets hidden.
+func (v *Value) Masked() string {
+	if v.Key.Secret && v.Value != "" {
+		return "********"
+	}
+
+	return v.Value
+}
+
+// Config is the resolved configuration.
+type Config struct {
+	Profile string
+	Values  []*Value
+	// dotEnv holds all keys of the .env file, including unknown ones.
+	dotEnv map[string]string
+}
+
+var flagValues = make(map[string]string)
+
+// applied are the environment variables set by Apply,
+// they must not be mistaken for the real environment when loading again.
+var applied = make(map[string]string)
+
+// SetFlag sets a key from a command line flag, name is
+// either the config name (e.g. minio.endpoint) or the environment variable.
+func SetFlag(name string, value string) error {
+	k := FindKey(name)
+	if k == nil {
+		return fmt.Errorf("unknown config key %q", name)
+	}
+
+	flagValues[k.Name] = value
+
+	return nil
+}
+
+// Load resolves all keys for the active profile, see ActiveProfile.
+func Load() (*Config, error) {
+	return LoadProfile("")
+}
+
+// LoadProfile resolves all keys for the profile, empty uses the active profile.
+// Values of the profile tables override the .env file and the values outside
+// of profiles in all files, the .env file ranks with the user config.
+func LoadProfile(profile string) (*Config, error) {
+	files, err := readFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	if profile == "" {
+		profile = activeProfile(files)
+	}
+
+	if !slices.Contains(profileNames(files), profile) {
+		return nil, fmt.Errorf(
+			"unknown profile %q (options: %s)",
+			profile,
+			strings.Join(profileNames(files), ", "),
+		)
+	}
+
+	c := &Config{Profile: profile, Values: make([]*Value, 0, len(Keys))}
+
+	layers := []*layer{{source: SourceFlag, values: flagValues}}
+
+	envValues := make(map[string]string)
+	for _, k := range Keys {
+		v, ok := os.LookupEnv(k.Env)
+		if !ok || v == "" {
+			continue
+		}
+
+		if a, wasApplied := applied[k.Env]; wasApplied && a == v {
+			continue
+		}
+
+		envValues[k.Name] = v
+	}
+
+	layers = append(layers, &layer{source: SourceEnv, values: envValues})
+
+	configDir, err := paths.Config()
+	if err != nil {
+		return nil, fmt.Errorf("could not find config dir: %w", err)
+	}
+
+	dotEnvPath := filepath.Join(configDir, DotEnvFileName)
+
+	c.dotEnv, err = godotenv.Read(dotEnvPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("could not read %s: %w", dotEnvPath, err)
+	}
+
+	dotEnvValues := make(map[string]string)
+	for env, v := range c.dotEnv {
+		if k := FindKey(env); k != nil && v != "" {
+			dotEnvValues[k.Name] = v
+		}
+	}
+
+	// the tables of the selected profile beat everything which is not
+	// profile specific, else a legacy .env would shadow every profile
+	for _, f := range files {
+		if values, ok := f.profiles[profile]; ok {
+			layers = append(layers, &layer{source: f.source, path: f.path, profile: profile, values: values})
+		}
+	}
+
+	// .env sits with the user config in the config dir,
+	// so the project config beats it like it beats the user config
+	for _, f := range files {
+		if f.source == SourceProject {
+			layers = append(layers, &layer{source: f.source, path: f.path, values: f.base})
+		}
+	}
+
+	layers = append(layers, &layer{source: SourceDotEnv, path: dotEnvPath, values: dotEnvValues})
+
+	for _, f := range files {
+		if f.source != SourceProject {
+			layers = append(layers, &layer{source: f.source, path: f.path, values: f.base})
+		}
+	}
+
+	secretLayer, err := loadSecrets(profile, layers)
+	if err != nil {
+		return nil, err
+	}
+
+	layers = append(layers, secretLayer)
+
+	for _, k := range Keys {
+		v := &Value{Key: k, Name: k.Name, Env: k.Env}
+
+		i := slices.IndexFunc(layers, func(l *layer) bool {
+			_, ok := l.values[k.Name]
+			return ok
+		})
+
+		switch {
+		case i != -1:
+			v.Value = layers[i].values[k.Name]
+			v.Source = layers[i].source
+			v.Path = layers[i].path
+			v.Profile = layers[i].profile
+
+			for _, l := range layers[i+1:] {
+				if _, ok := l.values[k.Name]; ok {
+					v.Shadowed = append(v.Shadowed, cmp.Or(l.path, l.source))
+				}
+			}
+		case k.Default != "":
+			v.Value = k.Default
+			v.Source = SourceDefault
+		}
+
+		c.Values = append(c.Values, v)
+	}
+
+	return c, nil
+}
+
+// layer is one source of values, earlier layers take precedence.
+type layer struct {
+	source  string
+	path    string
+	profile string
+	values  map[string]string
+}
+
+var secretStore secrets.Store
+
+// SecretStore returns the store secrets are read from, see secrets.Open.
+func SecretStore() (secrets.Store, error) {
+	if secretStore != nil {
+		return secretStore, nil
+	}
+
+	s, err := secrets.Open("")
+	if err != nil {
+		return nil, fmt.Errorf("could not open secret store: %w", err)
+	}
+
+	secretStore = s
+
+	return s, nil
+}
+
+// loadSecrets reads the secrets of the profile which no other layer sets
+// from the secret store. Only the secrets needed by the configured minio
+// credentials source and shortener are looked up, so the store is not opened
+// (or its passphrase asked for) unless one of them is missing.
+func loadSecrets(profile string, layers []*layer) (*layer, error) {
+	l := &layer{source: SourceSecrets, values: make(map[string]string)}
+
+	get := func(name string) string {
+		for _, l := range layers {
+			if v, ok := l.values[name]; ok {
+				return v
+			}
+		}
+
+		return FindKey(name).Default
+	}
+
+	for _, name := range neededSecrets(get) {
+		if get(name) != "" {
+			continue
+		}
+
+		store, err := SecretStore()
+		if err != nil {
+			return nil, err
+		}
+
+		l.path = store.Location()
+
+		k := FindKey(name)
+
+		v, err := store.Get(profile, k.Env)
+		if errors.Is(err, secrets.ErrNotFound) {
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("could not read secret %s from %s: %w", k.Name, store.Name(), err)
+		}
+
+		l.values[k.Name] = v
+	}
+
+	return l, nil
+}
+
+// neededSecrets returns the secret keys used with the configured values.
+// The values are those of the minio, shortener and yourls packages,
+// config only deals with strings and does not depend on them.
+func neededSecrets(get func(name string) string) []string {
+	var needed []string
+
+	switch get("minio.credentials_source") {
+	case "", "static", "sts", "chain":
+		needed = append(needed, "minio.access_secret")
+	}
+
+	switch get("shortener.name") {
+	case "yourls":
+		if get("yourls.auth_mode") == "password" {
+			needed = append(needed, "yourls.password")
+		} else {
+			needed = append(needed, "yourls.signature")
+		}
+	case "shlink":
+		needed = append(needed, "shlink.api_key")
+	}
+
+	return needed
+}
+
+// Getenv returns the resolved value of the environment variable,
+// unknown variables are looked up in the environment.
+// It can be used with env.LoadWith to load another profile.
+func (c *Config) Getenv(env string) string {
+	for _, v := range c.Values {
+		if v.Env == env {
+			return v.Value
+		}
+	}
+
+	return os.Getenv(env)
+}
+
+// Profiles returns the names of all configured profiles including DefaultProfile.
+func Profiles() ([]string, error) {
+	files, err := readFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	return profileNames(files), nil
+}
+
+// activeProfile returns the profile selected by MINLS_PROFILE (or --profile),
+// else the default profile of the config files, else DefaultProfile.
+func activeProfile(files []*file) string {
+	if p := os.Getenv(EnvProfile); p != "" {
+		return p
+	}
+
+	for _, f := range files {
+		if f.defaultProfile != "" {
+			return f.defaultProfile
+		}
+	}
+
+	return DefaultProfile
+}
+
+func profileNames(files []*file) []string {
+	names := []string{DefaultProfile}
+	for _, f := range files {
+		for name := range f.profiles {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+
+	slices.Sort(names[1:])
+
+	return names
+}
+
+// Apply exports the resolved values to the environment.
+// Defaults are left to the packages reading the values.
+// Secrets are left out so child processes (e.g. the clipboard
+// helpers) do not inherit them, use Getenv to read them.
+func (c *Config) Apply() error {
+	for env, v := range c.dotEnv {
+		// unknown .env keys keep working like before config files existed
+		if FindKey(env) == nil && os.Getenv(env) == "" {
+			err := os.Setenv(env, v)
+			if err != nil {
+				return fmt.Errorf("could not set %s: %w", env, err)
+			}
+		}
+	}
+
+	for _, v := range c.Values {
+		if !v.IsSet() || v.Source == SourceDefault || v.Source == SourceEnv || v.Key.Secret {
+			continue
+		}
+
+		err := os.Setenv(v.Env, v.Value)
+		if err != nil {
+			return fmt.Errorf("could not set %s: %w", v.Env, err)
+		}
+
+		applied[v.Env] = v.Value
+	}
+
+	return nil
+}
+
+// findProjectFile looks for the project config in the working dir and its parents.
+func findProjectFile() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("could not get working dir: %w", err)
+	}
+
+	for {
+		p := filepath.Join(dir, ProjectFileName)
+
+		_, err = os.Stat(p)
+		if err == nil {
+			return p, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+
+		dir = parent
+	}
+}
+
+// file is a parsed config file.
+type file struct {
+	source string
+	path   string
+	// base are the values outside of profiles.
+	base     map[string]string
+	profiles map[string]map[string]string
+	// defaultProfile is profiles.default.
+	defaultProfile string
+}
+
+// readFiles reads the project and user config files in order of precedence,
+// missing files are skipped.
+func readFiles() ([]*file, error) {
+	files := make([]*file, 0)
+
+	projectPath, err := findProjectFile()
+	if err != nil {
+		return nil, err
+	}
+
+	if projectPath != "" {
+		f, readErr := readFile(SourceProject, projectPath)
+		if readErr != nil {
+			return nil, readErr
+		}
+
+		files = append(files, f)
+	}
+
+	configDir, err := paths.Config()
+	if err != nil {
+		return nil, fmt.Errorf("could not find config dir: %w", err)
+	}
+
+	f, err := readFile(SourceUser, filepath.Join(configDir, UserFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return files, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return append(files, f), nil
+}
+
+// readFile reads a TOML config file into config names and string values.
+//
+//	[minio]
+//	endpoint = "https://minio.example.com"
+//
+//	[profiles]
+//	default = "team"
+//
+//	[profiles.team.minio]
+//	endpoint = "https://minio.team.example.com"
+func readFile(source string, path string) (*file, error) {
+	raw := make(map[string]any)
+
+	_, err := toml.DecodeFile(path, &raw)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+
+		return nil, fmt.Errorf("could not parse %s: %w", path, err)
+	}
+
+	f := &file{
+		source:   source,
+		path:     path,
+		base:     make(map[string]string),
+		profiles: make(map[string]map[string]string),
+	}
+
+	profiles, _ := raw[profilesTable].(map[string]any)
+	delete(raw, profilesTable)
+
+	err = readSections(raw, f.base)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	for name, v := range profiles {
+		if name == "default" {
+			f.defaultProfile, _ = v.(string)
+			continue
+		}
+
+		sections, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf(
+				"%s: profile %s must be a table like [profiles.%s.minio]",
+				path,
+				name,
+				name,
+			)
+		}
+
+		f.profiles[name] = make(map[string]string)
+
+		err = readSections(sections, f.profiles[name])
+		if err != nil {
+			return nil, fmt.Errorf("%s: profile %s: %w", path, name, err)
+		}
+	}
+
+	return f, nil
+}
+
+func readSections(raw map[string]any, values map[string]string) error {
+	for section, table := range raw {
+		t, ok := table.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s must be a table like [%s]", section, section)
+		}
+
+		for name, v := range t {
+			key := section + "." + name
+			if FindKey(key) == nil {
+				return fmt.Errorf("unknown key %s", key)
+			}
+
+			s, err := stringValue(v)
+			if err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+
+			values[key] = s
+		}
+	}
+
+	return nil
+}
+
+func stringValue(v any) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	default:
+		return "", fmt.Errorf("unsupported value type %T", v)
+	}
+}
@@ -0,0 +1,326 @@
+package config
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/devusSs/minls/internal/paths"
+	"github.com/devusSs/minls/internal/secrets"
+)
+
+// setupFiles writes the config files into a temporary config and working
+// dir and isolates the test from the real environment.
+func setupFiles(t *testing.T, dotEnv string, user string, project string) {
+	t.Helper()
+
+	configDir := t.TempDir()
+	workDir := t.TempDir()
+
+	t.Setenv(paths.EnvConfigDir, configDir)
+	t.Setenv(secrets.EnvBackend, secrets.BackendNone)
+	t.Setenv(EnvProfile, "")
+	t.Chdir(workDir)
+
+	for _, k := range Keys {
+		t.Setenv(k.Env, "")
+	}
+
+	flagValues = make(map[string]string)
+	applied = make(map[string]string)
+	secretStore = nil
+	t.Cleanup(func() {
+		flagValues = make(map[string]string)
+		secretStore = nil
+	})
+
+	files := map[string]string{
+		filepath.Join(configDir, DotEnvFileName): dotEnv,
+		filepath.Join(configDir, UserFileName):   user,
+		filepath.Join(workDir, ProjectFileName):  project,
+	}
+
+	for path, content := range files {
+		if content == "" {
+			continue
+		}
+
+		err := os.WriteFile(path, []byte(content), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoadProfilePrecedence(t *testing.T) {
+	const (
+		dotEnv = "MINIO_ENDPOINT=dotenv\nMINIO_BUCKET_PUBLIC=dotenv-public\nMINIO_BUCKET_PRIVATE=dotenv-private\n"
+		user   = `
+[minio]
+endpoint = "user"
+bucket_private = "user-private"
+access_key = "user-key"
+
+[profiles.team.minio]
+endpoint = "user-team"
+access_key = "user-team-key"
+`
+		project = `
+[minio]
+bucket_private = "project-private"
+
+[profiles.team.minio]
+access_key = "project-team-key"
+`
+	)
+
+	tests := []struct {
+		name    string
+		profile string
+		env     map[string]string
+		flags   map[string]string
+		key     string
+		want    string
+		source  string
+	}{
+		{"dotenv beats user", DefaultProfile, nil, nil, "minio.endpoint", "dotenv", SourceDotEnv},
+		{"project beats dotenv", DefaultProfile, nil, nil, "minio.bucket_private", "project-private", SourceProject},
+		{"project base beats user base", DefaultProfile, nil, nil, "minio.bucket_private", "project-private", SourceProject},
+		{"user base without override", DefaultProfile, nil, nil, "minio.access_key", "user-key", SourceUser},
+		{"default value", DefaultProfile, nil, nil, "log.level", "info", SourceDefault},
+		{"profile beats dotenv", "team", nil, nil, "minio.endpoint", "user-team", SourceUser},
+		{"project profile beats user profile", "team", nil, nil, "minio.access_key", "project-team-key", SourceProject},
+		{"dotenv applies to profiles", "team", nil, nil, "minio.bucket_public", "dotenv-public", SourceDotEnv},
+		{
+			"env beats profile",
+			"team",
+			map[string]string{"MINIO_ENDPOINT": "env"},
+			nil,
+			"minio.endpoint",
+			"env",
+			SourceEnv,
+		},
+		{
+			"flag beats env",
+			"team",
+			map[string]string{"MINIO_ENDPOINT": "env"},
+			map[string]string{"minio.endpoint": "flag"},
+			"minio.endpoint",
+			"flag",
+			SourceFlag,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupFiles(t, dotEnv, user, project)
+
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			for k, v := range tt.flags {
+				err := SetFlag(k, v)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			cfg, err := LoadProfile(tt.profile)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, v := range cfg.Values {
+				if v.Name != tt.key {
+					continue
+				}
+
+				if v.Value != tt.want || v.Source != tt.source {
+					t.Errorf("%s = %q from %s, want %q from %s", tt.key, v.Value, v.Source, tt.want, tt.source)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadProfileSelection(t *testing.T) {
+	setupFiles(t, "", "[profiles]\ndefault = \"team\"\n\n[profiles.team.minio]\nendpoint = \"team\"\n", "")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Profile != "team" {
+		t.Errorf("profile = %q, want team from profiles.default", cfg.Profile)
+	}
+
+	t.Setenv(EnvProfile, DefaultProfile)
+
+	cfg, err = Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Profile != DefaultProfile {
+		t.Errorf("profile = %q, want %s from %s", cfg.Profile, DefaultProfile, EnvProfile)
+	}
+
+	t.Setenv(EnvProfile, "missing")
+
+	_, err = Load()
+	if err == nil {
+		t.Error("unknown profile did not fail")
+	}
+}
+
+// fakeStore is an in memory secret store recording the looked up keys.
+type fakeStore struct {
+	values map[string]string
+	gets   []string
+}
+
+func (s *fakeStore) Name() string     { return "fake" }
+func (s *fakeStore) Location() string { return "fake" }
+
+func (s *fakeStore) Get(profile string, key string) (string, error) {
+	s.gets = append(s.gets, key)
+
+	v, ok := s.values[profile+"/"+key]
+	if !ok {
+		return "", secrets.ErrNotFound
+	}
+
+	return v, nil
+}
+
+func (s *fakeStore) Set(profile string, key string, value string) error {
+	s.values[profile+"/"+key] = value
+	return nil
+}
+
+func (s *fakeStore) Delete(profile string, key string) error {
+	delete(s.values, profile+"/"+key)
+	return nil
+}
+
+func TestLoadProfileSecrets(t *testing.T) {
+	stored := map[string]string{
+		"default/MINIO_ACCESS_SECRET": "stored-secret",
+		"default/YOURLS_SIGNATURE":    "stored-signature",
+		"default/YOURLS_PASSWORD":     "stored-password",
+		"default/SHLINK_API_KEY":      "stored-api-key",
+	}
+
+	tests := []struct {
+		name   string
+		dotEnv string
+		user   string
+		want   map[string]string
+		gets   []string
+	}{
+		{
+			name: "defaults need access secret and signature",
+			want: map[string]string{
+				"minio.access_secret": "stored-secret",
+				"yourls.signature":    "stored-signature",
+				"yourls.password":     "",
+				"shlink.api_key":      "",
+			},
+			gets: []string{"MINIO_ACCESS_SECRET", "YOURLS_SIGNATURE"},
+		},
+		{
+			name:   "dotenv beats store",
+			dotEnv: "MINIO_ACCESS_SECRET=dotenv-secret\nYOURLS_SIGNATURE=dotenv-signature\n",
+			want: map[string]string{
+				"minio.access_secret": "dotenv-secret",
+				"yourls.signature":    "dotenv-signature",
+			},
+		},
+		{
+			name: "password auth",
+			user: "[minio]\ncredentials_source = \"mc\"\n\n[yourls]\nauth_mode = \"password\"\n",
+			want: map[string]string{
+				"minio.access_secret": "",
+				"yourls.signature":    "",
+				"yourls.password":     "stored-password",
+			},
+			gets: []string{"YOURLS_PASSWORD"},
+		},
+		{
+			name: "shlink",
+			user: "[shortener]\nname = \"shlink\"\n",
+			want: map[string]string{
+				"yourls.signature": "",
+				"shlink.api_key":   "stored-api-key",
+			},
+			gets: []string{"MINIO_ACCESS_SECRET", "SHLINK_API_KEY"},
+		},
+		{
+			name: "nothing needed",
+			user: "[minio]\ncredentials_source = \"aws\"\n\n[shortener]\nname = \"none\"\n",
+			want: map[string]string{"minio.access_secret": ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupFiles(t, tt.dotEnv, tt.user, "")
+
+			store := &fakeStore{values: maps.Clone(stored)}
+			secretStore = store
+
+			cfg, err := Load()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for key, want := range tt.want {
+				got := cfg.Getenv(FindKey(key).Env)
+				if got != want {
+					t.Errorf("%s = %q, want %q", key, got, want)
+				}
+			}
+
+			if !slices.Equal(store.gets, tt.gets) {
+				t.Errorf("looked up %v, want %v", store.gets, tt.gets)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	setupFiles(t, "", "[minio]\nendpoint = \"user\"\naccess_secret = \"user-secret\"\n", "")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = cfg.Apply()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		env      string
+		exported string
+		resolved string
+	}{
+		{env: "MINIO_ENDPOINT", exported: "user", resolved: "user"},
+		{env: "MINIO_ACCESS_SECRET", exported: "", resolved: "user-secret"},
+	}
+
+	for _, tt := range tests {
+		if got := os.Getenv(tt.env); got != tt.exported {
+			t.Errorf("exported %s = %q, want %q", tt.env, got, tt.exported)
+		}
+
+		if got := cfg.Getenv(tt.env); got != tt.resolved {
+			t.Errorf("resolved %s = %q, want %q", tt.env, got, tt.resolved)
+		}
+	}
+}
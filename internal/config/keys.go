@@ -0,0 +1,72 @@
+package config
+
+import (
+	"github.com/devusSs/minls/internal/log"
+)
+
+// Key is a configuration key. In config files it is written as
+// <section>.<name> (a TOML table and key), in the environment as Env.
+type Key struct {
+	Name    string
+	Env     string
+	Default string
+	// Secret values are masked when the config is shown.
+	Secret bool
+}
+
+// Keys are all known configuration keys.
+//
+// The defaults are the defaults of the packages using the values,
+// written out so config does not depend on those packages.
+var Keys = []*Key{
+	{Name: "minio.endpoint", Env: "MINIO_ENDPOINT"},
+	{Name: "minio.access_key", Env: "MINIO_ACCESS_KEY"},
+	{Name: "minio.access_secret", Env: "MINIO_ACCESS_SECRET", Secret: true},
+	{Name: "minio.credentials_source", Env: "MINIO_CREDENTIALS_SOURCE", Default: "static"},
+	{Name: "minio.mc_config_file", Env: "MINIO_MC_CONFIG_FILE"},
+	{Name: "minio.mc_alias", Env: "MINIO_MC_ALIAS"},
+	{Name: "minio.aws_credentials_file", Env: "MINIO_AWS_CREDENTIALS_FILE"},
+	{Name: "minio.aws_profile", Env: "MINIO_AWS_PROFILE"},
+	{Name: "minio.sts_endpoint", Env: "MINIO_STS_ENDPOINT"},
+	{Name: "minio.sts_role_arn", Env: "MINIO_STS_ROLE_ARN"},
+	{Name: "minio.sts_duration", Env: "MINIO_STS_DURATION", Default: "1h"},
+	{Name: "minio.ca_bundle", Env: "MINIO_CA_BUNDLE"},
+	{Name: "minio.client_cert", Env: "MINIO_CLIENT_CERT"},
+	{Name: "minio.client_key", Env: "MINIO_CLIENT_KEY"},
+	{Name: "minio.insecure_skip_verify", Env: "MINIO_INSECURE_SKIP_VERIFY", Default: "false"},
+	{Name: "minio.bucket_lookup", Env: "MINIO_BUCKET_LOOKUP", Default: "auto"},
+	{Name: "minio.bucket_public", Env: "MINIO_BUCKET_PUBLIC", Default: "minls-public"},
+	{Name: "minio.bucket_private", Env: "MINIO_BUCKET_PRIVATE", Default: "minls-private"},
+	{Name: "minio.link_expiry", Env: "MINIO_LINK_EXPIRY", Default: "168h0m0s"},
+	{Name: "shortener.name", Env: "SHORTENER", Default: "yourls"},
+	{Name: "shortener.keyword_style", Env: "SHORTENER_KEYWORD_STYLE", Default: "uuid"},
+	{Name: "shortener.keyword_length", Env: "SHORTENER_KEYWORD_LENGTH", Default: "6"},
+	{Name: "yourls.endpoint", Env: "YOURLS_ENDPOINT"},
+	{Name: "yourls.auth_mode", Env: "YOURLS_AUTH_MODE", Default: "signature"},
+	{Name: "yourls.signature", Env: "YOURLS_SIGNATURE", Secret: true},
+	{Name: "yourls.signature_hash", Env: "YOURLS_SIGNATURE_HASH", Default: "md5"},
+	{Name: "yourls.username", Env: "YOURLS_USERNAME"},
+	{Name: "yourls.password", Env: "YOURLS_PASSWORD", Secret: true},
+	{Name: "yourls.timeout", Env: "YOURLS_TIMEOUT", Default: "30s"},
+	{Name: "yourls.retries", Env: "YOURLS_RETRIES", Default: "3"},
+	{Name: "yourls.proxy", Env: "YOURLS_PROXY"},
+	{Name: "yourls.ca_bundle", Env: "YOURLS_CA_BUNDLE"},
+	{Name: "yourls.pinned_cert", Env: "YOURLS_PINNED_CERT"},
+	{Name: "shlink.endpoint", Env: "SHLINK_ENDPOINT"},
+	{Name: "shlink.api_key", Env: "SHLINK_API_KEY", Secret: true},
+	{Name: "data.retention", Env: "DATA_RETENTION", Default: "expiry"},
+	{Name: "search.index_content", Env: "SEARCH_INDEX_CONTENT", Default: "false"},
+	{Name: "log.level", Env: "LOG_LEVEL", Default: "info"},
+	{Name: "log.redact_patterns", Env: log.EnvRedactPatterns},
+}
+
+// FindKey returns the key by its config name or environment variable, nil if unknown.
+func FindKey(name string) *Key {
+	for _, k := range Keys {
+		if k.Name == name || k.Env == name {
+			return k
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/devusSs/minls/internal/atomicfile"
+	"github.com/devusSs/minls/internal/paths"
+)
+
+// WriteUser sets the values (keyed by config name) in the user config file
+// and returns its path. Values of profiles other than DefaultProfile are
+// written to their profile table, other keys of the file are kept.
+//
+// Comments of an existing file are lost since the file is re-encoded.
+func WriteUser(profile string, values map[string]string) (string, error) {
+	configDir, err := paths.Config()
+	if err != nil {
+		return "", fmt.Errorf("could not find config dir: %w", err)
+	}
+
+	path := filepath.Join(configDir, UserFileName)
+
+	raw := make(map[string]any)
+
+	_, err = toml.DecodeFile(path, &raw)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("could not parse %s: %w", path, err)
+	}
+
+	root := raw
+	if profile != "" && profile != DefaultProfile {
+		root = subTable(subTable(raw, profilesTable), profile)
+	}
+
+	for name, v := range values {
+		if FindKey(name) == nil {
+			return "", fmt.Errorf("unknown config key %q", name)
+		}
+
+		section, key, _ := strings.Cut(name, ".")
+		subTable(root, section)[key] = v
+	}
+
+	err = os.MkdirAll(configDir, 0700)
+	if err != nil {
+		return "", fmt.Errorf("could not create config dir: %w", err)
+	}
+
+	err = atomicfile.Write(path, 0600, func(w io.Writer) error {
+		return toml.NewEncoder(w).Encode(raw)
+	})
+	if err != nil {
+		return "", fmt.Errorf("could not write %s: %w", path, err)
+	}
+
+	return path, nil
+}
+
+// subTable returns the table t[name], creating it if missing.
+func subTable(t map[string]any, name string) map[string]any {
+	sub, ok := t[name].(map[string]any)
+	if !ok {
+		sub = make(map[string]any)
+		t[name] = sub
+	}
+
+	return sub
+}
@@ -3,79 +3,328 @@ package env
 import (
 	"fmt"
 	"log/slog"
-	"os"
-	"path/filepath"
-
-	"github.com/joho/godotenv"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/devusSs/minls/internal/config"
 	"github.com/devusSs/minls/internal/log"
+	"github.com/devusSs/minls/internal/minio"
+	"github.com/devusSs/minls/internal/shortener"
+	"github.com/devusSs/minls/internal/yourls"
 )
 
+// Env holds the resolved configuration, the env tag names the key of a field.
+// Fields of secret keys (see config.Key) are masked when the Env is logged.
 type Env struct {
-	MinioEndpoint     string `json:"minio_endpoint,omitempty"`
-	MinioAccessKey    string `json:"minio_access_key,omitempty"`
-	MinioAccessSecret string `json:"minio_access_secret,omitempty"`
-	YOURLSEndpoint    string `json:"yourls_endpoint,omitempty"`
-	YOURLSSignature   string `json:"yourls_signature,omitempty"`
+	MinioEndpoint           string        `json:"minio_endpoint,omitempty" env:"MINIO_ENDPOINT"`
+	MinioAccessKey          string        `json:"minio_access_key,omitempty" env:"MINIO_ACCESS_KEY"`
+	MinioAccessSecret       string        `json:"minio_access_secret,omitempty" env:"MINIO_ACCESS_SECRET"`
+	MinioCABundle           string        `json:"minio_ca_bundle,omitempty" env:"MINIO_CA_BUNDLE"`
+	MinioClientCert         string        `json:"minio_client_cert,omitempty" env:"MINIO_CLIENT_CERT"`
+	MinioClientKey          string        `json:"minio_client_key,omitempty" env:"MINIO_CLIENT_KEY"`
+	MinioInsecureSkipVerify bool          `json:"minio_insecure_skip_verify,omitempty" env:"MINIO_INSECURE_SKIP_VERIFY"`
+	MinioBucketLookup       string        `json:"minio_bucket_lookup,omitempty" env:"MINIO_BUCKET_LOOKUP"`
+	MinioCredentialsSource  string        `json:"minio_credentials_source,omitempty" env:"MINIO_CREDENTIALS_SOURCE"`
+	MinioMCConfigFile       string        `json:"minio_mc_config_file,omitempty" env:"MINIO_MC_CONFIG_FILE"`
+	MinioMCAlias            string        `json:"minio_mc_alias,omitempty" env:"MINIO_MC_ALIAS"`
+	MinioAWSCredentialsFile string        `json:"minio_aws_credentials_file,omitempty" env:"MINIO_AWS_CREDENTIALS_FILE"`
+	MinioAWSProfile         string        `json:"minio_aws_profile,omitempty" env:"MINIO_AWS_PROFILE"`
+	MinioSTSEndpoint        string        `json:"minio_sts_endpoint,omitempty" env:"MINIO_STS_ENDPOINT"`
+	MinioSTSRoleARN         string        `json:"minio_sts_role_arn,omitempty" env:"MINIO_STS_ROLE_ARN"`
+	MinioSTSDuration        time.Duration `json:"minio_sts_duration,omitempty" env:"MINIO_STS_DURATION"`
+	MinioBucketPublic       string        `json:"minio_bucket_public,omitempty" env:"MINIO_BUCKET_PUBLIC"`
+	MinioBucketPrivate      string        `json:"minio_bucket_private,omitempty" env:"MINIO_BUCKET_PRIVATE"`
+	MinioLinkExpiry         time.Duration `json:"minio_link_expiry,omitempty" env:"MINIO_LINK_EXPIRY"`
+	Shortener               string        `json:"shortener,omitempty" env:"SHORTENER"`
+	KeywordStyle            string        `json:"keyword_style,omitempty" env:"SHORTENER_KEYWORD_STYLE"`
+	KeywordLength           int           `json:"keyword_length,omitempty" env:"SHORTENER_KEYWORD_LENGTH"`
+	YOURLSEndpoint          string        `json:"yourls_endpoint,omitempty" env:"YOURLS_ENDPOINT"`
+	YOURLSAuthMode          string        `json:"yourls_auth_mode,omitempty" env:"YOURLS_AUTH_MODE"`
+	YOURLSSignature         string        `json:"yourls_signature,omitempty" env:"YOURLS_SIGNATURE"`
+	YOURLSHash              string        `json:"yourls_hash,omitempty" env:"YOURLS_SIGNATURE_HASH"`
+	YOURLSUsername          string        `json:"yourls_username,omitempty" env:"YOURLS_USERNAME"`
+	YOURLSPassword          string        `json:"yourls_password,omitempty" env:"YOURLS_PASSWORD"`
+	YOURLSTimeout           time.Duration `json:"yourls_timeout,omitempty" env:"YOURLS_TIMEOUT"`
+	YOURLSRetries           int           `json:"yourls_retries,omitempty" env:"YOURLS_RETRIES"`
+	YOURLSProxy             string        `json:"yourls_proxy,omitempty" env:"YOURLS_PROXY"`
+	YOURLSCABundle          string        `json:"yourls_ca_bundle,omitempty" env:"YOURLS_CA_BUNDLE"`
+	YOURLSPinnedCert        string        `json:"yourls_pinned_cert,omitempty" env:"YOURLS_PINNED_CERT"`
+	ShlinkEndpoint          string        `json:"shlink_endpoint,omitempty" env:"SHLINK_ENDPOINT"`
+	ShlinkAPIKey            string        `json:"shlink_api_key,omitempty" env:"SHLINK_API_KEY"`
+	SearchIndexContent      bool          `json:"search_index_content,omitempty" env:"SEARCH_INDEX_CONTENT"`
+
+	// getenv looks up the keys, see LoadWith.
+	getenv func(key string) string
 }
 
-func Load() (*Env, error) {
-	exe, err := os.Executable()
+// LoadWith loads the keys using getenv, usually config.Config.Getenv
+// since secrets are not exported to the environment (see config.Apply).
+func LoadWith(getenv func(key string) string) (*Env, error) {
+	var err error
+
+	env := &Env{getenv: getenv}
+
+	env.MinioEndpoint, err = env.loadKey("MINIO_ENDPOINT")
 	if err != nil {
-		return nil, fmt.Errorf("could not find executable: %w", err)
+		return nil, fmt.Errorf("could not get MINIO_ENDPOINT: %w", err)
 	}
 
-	log.Debug("env - Load", slog.String("action", "find_executable"), slog.String("exe_path", exe))
+	err = env.loadMinioCredentials()
+	if err != nil {
+		return nil, fmt.Errorf("could not load minio credentials: %w", err)
+	}
 
-	envFilePath := filepath.Join(filepath.Dir(exe), ".env")
-	log.Debug(
-		"env - Load",
-		slog.String("action", "godotenv_load"),
-		slog.String("env_file_path", envFilePath),
-	)
+	err = env.loadMinioTLS()
+	if err != nil {
+		return nil, fmt.Errorf("could not load minio tls: %w", err)
+	}
 
-	// we can ignore the load error since we dont mind
-	// whether an .env file is actually present or not
-	err = godotenv.Load(envFilePath)
-	log.Debug("env - Load", slog.String("action", "godotenv_load"), slog.Any("err", err))
+	env.Shortener = env.loadKeyDefault("SHORTENER")
 
-	env := &Env{}
+	env.KeywordStyle = env.loadKeyDefault("SHORTENER_KEYWORD_STYLE")
 
-	env.MinioEndpoint, err = loadKey("MINIO_ENDPOINT")
+	env.KeywordLength, err = strconv.Atoi(env.loadKeyDefault("SHORTENER_KEYWORD_LENGTH"))
 	if err != nil {
-		return nil, fmt.Errorf("could not get MINIO_ENDPOINT: %w", err)
+		return nil, fmt.Errorf("could not parse SHORTENER_KEYWORD_LENGTH: %w", err)
 	}
 
-	env.MinioAccessKey, err = loadKey("MINIO_ACCESS_KEY")
+	err = env.loadShortener()
 	if err != nil {
-		return nil, fmt.Errorf("could not get MINIO_ACCESS_KEY: %w", err)
+		return nil, fmt.Errorf("could not load shortener %s: %w", env.Shortener, err)
 	}
 
-	env.MinioAccessSecret, err = loadKey("MINIO_ACCESS_SECRET")
+	env.SearchIndexContent, err = strconv.ParseBool(env.loadKeyDefault("SEARCH_INDEX_CONTENT"))
 	if err != nil {
-		return nil, fmt.Errorf("could not get MINIO_ACCESS_SECRET: %w", err)
+		return nil, fmt.Errorf("could not parse SEARCH_INDEX_CONTENT: %w", err)
+	}
+
+	return env, nil
+}
+
+// Getenv looks up key the same way the Env was loaded,
+// e.g. for keys the Env does not hold.
+func (e *Env) Getenv(key string) string {
+	return e.getenv(key)
+}
+
+// loadMinioCredentials loads the keys needed by the configured
+// MinIO credentials source. The access key and secret are only
+// required for the static and sts sources.
+func (e *Env) loadMinioCredentials() error {
+	var err error
+
+	e.MinioCredentialsSource = e.loadKeyDefault("MINIO_CREDENTIALS_SOURCE")
+
+	if minio.RequiresStaticKeys(e.MinioCredentialsSource) {
+		e.MinioAccessKey, err = e.loadKey("MINIO_ACCESS_KEY")
+		if err != nil {
+			return fmt.Errorf("could not get MINIO_ACCESS_KEY: %w", err)
+		}
+
+		e.MinioAccessSecret, err = e.loadKey("MINIO_ACCESS_SECRET")
+		if err != nil {
+			return fmt.Errorf("could not get MINIO_ACCESS_SECRET: %w", err)
+		}
+	} else {
+		// optional, the chain source falls back to them
+		e.MinioAccessKey = e.getenv("MINIO_ACCESS_KEY")
+		e.MinioAccessSecret = e.getenv("MINIO_ACCESS_SECRET")
 	}
 
-	env.YOURLSEndpoint, err = loadKey("YOURLS_ENDPOINT")
+	e.MinioMCConfigFile = e.getenv("MINIO_MC_CONFIG_FILE")
+	e.MinioMCAlias = e.getenv("MINIO_MC_ALIAS")
+	e.MinioAWSCredentialsFile = e.getenv("MINIO_AWS_CREDENTIALS_FILE")
+	e.MinioAWSProfile = e.getenv("MINIO_AWS_PROFILE")
+	e.MinioSTSEndpoint = e.getenv("MINIO_STS_ENDPOINT")
+	e.MinioSTSRoleARN = e.getenv("MINIO_STS_ROLE_ARN")
+
+	e.MinioSTSDuration, err = time.ParseDuration(e.loadKeyDefault("MINIO_STS_DURATION"))
+	if err != nil {
+		return fmt.Errorf("could not parse MINIO_STS_DURATION: %w", err)
+	}
+
+	return nil
+}
+
+// loadMinioTLS loads the optional MinIO TLS, addressing and bucket keys.
+func (e *Env) loadMinioTLS() error {
+	var err error
+
+	e.MinioCABundle = e.getenv("MINIO_CA_BUNDLE")
+	e.MinioClientCert = e.getenv("MINIO_CLIENT_CERT")
+	e.MinioClientKey = e.getenv("MINIO_CLIENT_KEY")
+	e.MinioBucketLookup = e.loadKeyDefault("MINIO_BUCKET_LOOKUP")
+	e.MinioBucketPublic = e.loadKeyDefault("MINIO_BUCKET_PUBLIC")
+	e.MinioBucketPrivate = e.loadKeyDefault("MINIO_BUCKET_PRIVATE")
+
+	e.MinioLinkExpiry, err = time.ParseDuration(e.loadKeyDefault("MINIO_LINK_EXPIRY"))
 	if err != nil {
-		return nil, fmt.Errorf("could not get YOURLS_ENDPOINT: %w", err)
+		return fmt.Errorf("could not parse MINIO_LINK_EXPIRY: %w", err)
 	}
 
-	env.YOURLSSignature, err = loadKey("YOURLS_SIGNATURE")
+	e.MinioInsecureSkipVerify, err = strconv.ParseBool(e.loadKeyDefault("MINIO_INSECURE_SKIP_VERIFY"))
 	if err != nil {
-		return nil, fmt.Errorf("could not get YOURLS_SIGNATURE: %w", err)
+		return fmt.Errorf("could not parse MINIO_INSECURE_SKIP_VERIFY: %w", err)
 	}
 
-	return env, nil
+	return nil
 }
 
-func loadKey(key string) (string, error) {
-	v := os.Getenv(key)
+// loadShortener loads the keys needed by the configured shortener.
+func (e *Env) loadShortener() error {
+	var err error
+
+	switch e.Shortener {
+	case shortener.NameNone:
+		return nil
+	case shortener.NameYOURLS:
+		e.YOURLSEndpoint, err = e.loadKey("YOURLS_ENDPOINT")
+		if err != nil {
+			return fmt.Errorf("could not get YOURLS_ENDPOINT: %w", err)
+		}
+
+		err = e.loadYOURLSTransport()
+		if err != nil {
+			return fmt.Errorf("could not load yourls transport: %w", err)
+		}
+
+		return e.loadYOURLSAuth()
+	case shortener.NameShlink:
+		e.ShlinkEndpoint, err = e.loadKey("SHLINK_ENDPOINT")
+		if err != nil {
+			return fmt.Errorf("could not get SHLINK_ENDPOINT: %w", err)
+		}
+
+		e.ShlinkAPIKey, err = e.loadKey("SHLINK_API_KEY")
+		if err != nil {
+			return fmt.Errorf("could not get SHLINK_API_KEY: %w", err)
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("unknown shortener: %s", e.Shortener)
+	}
+}
+
+// loadYOURLSAuth loads the keys needed by the configured YOURLS auth mode.
+func (e *Env) loadYOURLSAuth() error {
+	var err error
+
+	e.YOURLSAuthMode = e.loadKeyDefault("YOURLS_AUTH_MODE")
+
+	switch e.YOURLSAuthMode {
+	case yourls.AuthModeSignature, yourls.AuthModeTimedSignature:
+		e.YOURLSSignature, err = e.loadKey("YOURLS_SIGNATURE")
+		if err != nil {
+			return fmt.Errorf("could not get YOURLS_SIGNATURE: %w", err)
+		}
+
+		e.YOURLSHash = e.loadKeyDefault("YOURLS_SIGNATURE_HASH")
+
+		return nil
+	case yourls.AuthModePassword:
+		e.YOURLSUsername, err = e.loadKey("YOURLS_USERNAME")
+		if err != nil {
+			return fmt.Errorf("could not get YOURLS_USERNAME: %w", err)
+		}
+
+		e.YOURLSPassword, err = e.loadKey("YOURLS_PASSWORD")
+		if err != nil {
+			return fmt.Errorf("could not get YOURLS_PASSWORD: %w", err)
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("unknown YOURLS_AUTH_MODE: %s", e.YOURLSAuthMode)
+	}
+}
+
+// loadYOURLSTransport loads the optional YOURLS transport keys.
+func (e *Env) loadYOURLSTransport() error {
+	var err error
+
+	e.YOURLSTimeout, err = time.ParseDuration(e.loadKeyDefault("YOURLS_TIMEOUT"))
+	if err != nil {
+		return fmt.Errorf("could not parse YOURLS_TIMEOUT: %w", err)
+	}
+
+	e.YOURLSRetries, err = strconv.Atoi(e.loadKeyDefault("YOURLS_RETRIES"))
+	if err != nil {
+		return fmt.Errorf("could not parse YOURLS_RETRIES: %w", err)
+	}
+
+	e.YOURLSProxy = e.getenv("YOURLS_PROXY")
+	e.YOURLSCABundle = e.getenv("YOURLS_CA_BUNDLE")
+	e.YOURLSPinnedCert = e.getenv("YOURLS_PINNED_CERT")
+
+	return nil
+}
+
+// LogValue implements slog.LogValuer, unset fields are left out
+// and fields of secret keys are masked.
+func (e *Env) LogValue() slog.Value {
+	v := reflect.ValueOf(e).Elem()
+	t := v.Type()
+
+	attrs := make([]slog.Attr, 0, t.NumField())
+
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() || v.Field(i).IsZero() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+
+		if isSecret(f.Tag.Get("env")) {
+			attrs = append(attrs, slog.String(name, log.Mask))
+			continue
+		}
+
+		attrs = append(attrs, slog.Any(name, v.Field(i).Interface()))
+	}
+
+	return slog.GroupValue(attrs...)
+}
+
+// isSecret reports whether the values of the key are never logged.
+func isSecret(key string) bool {
+	k := config.FindKey(key)
+	return k != nil && k.Secret
+}
+
+// logValue returns the value of the key for logging.
+func logValue(key string, v string) string {
+	if isSecret(key) && v != "" {
+		return log.Mask
+	}
+
+	return v
+}
+
+func (e *Env) loadKey(key string) (string, error) {
+	v := e.getenv(key)
 	if v == "" {
 		return "", fmt.Errorf("key %s could not be found", key)
 	}
 
-	log.Debug("env - loadKey", slog.String("key", key), slog.String("v", v))
+	log.Debug("env - loadKey", slog.String("key", key), slog.String("v", logValue(key, v)))
 
 	return v, nil
 }
+
+// loadKeyDefault loads the key, falling back to its default in config.Keys.
+func (e *Env) loadKeyDefault(key string) string {
+	v := e.getenv(key)
+	if v == "" {
+		if k := config.FindKey(key); k != nil {
+			v = k.Default
+		}
+	}
+
+	log.Debug("env - loadKeyDefault", slog.String("key", key), slog.String("v", logValue(key, v)))
+
+	return v
+}
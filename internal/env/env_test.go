@@ -0,0 +1,62 @@
+package env
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/devusSs/minls/internal/config"
+	"github.com/devusSs/minls/internal/log"
+)
+
+func TestFieldsHaveKeys(t *testing.T) {
+	typ := reflect.TypeFor[Env]()
+
+	for i := range typ.NumField() {
+		f := typ.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		if config.FindKey(f.Tag.Get("env")) == nil {
+			t.Errorf("field %s has no config key (env tag %q)", f.Name, f.Tag.Get("env"))
+		}
+	}
+}
+
+func TestLoadWith(t *testing.T) {
+	values := map[string]string{
+		"MINIO_ENDPOINT":      "localhost:9000",
+		"MINIO_ACCESS_KEY":    "key",
+		"MINIO_ACCESS_SECRET": "secret",
+		"YOURLS_ENDPOINT":     "https://s.example.com",
+		"YOURLS_SIGNATURE":    "signature",
+	}
+
+	e, err := LoadWith(func(key string) string { return values[key] })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	attrs := make(map[string]string)
+	for _, a := range e.LogValue().Group() {
+		attrs[a.Key] = a.Value.String()
+	}
+
+	tests := []struct {
+		attr string
+		want string
+	}{
+		{attr: "minio_access_key", want: "key"},
+		{attr: "minio_access_secret", want: log.Mask},
+		{attr: "yourls_signature", want: log.Mask},
+		{attr: "shortener", want: config.FindKey("SHORTENER").Default},
+		{attr: "minio_bucket_public", want: config.FindKey("MINIO_BUCKET_PUBLIC").Default},
+		{attr: "yourls_timeout", want: config.FindKey("YOURLS_TIMEOUT").Default},
+	}
+
+	for _, tt := range tests {
+		if got := attrs[tt.attr]; got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.attr, got, tt.want)
+		}
+	}
+}
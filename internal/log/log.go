@@ -1,6 +1,7 @@
 package log
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -8,6 +9,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/devusSs/minls/internal/paths"
 )
 
 func Init() error {
@@ -25,6 +28,11 @@ func Init() error {
 		level = "info"
 	}
 
+	err = loadRedactPatterns()
+	if err != nil {
+		return err
+	}
+
 	err = createFileLogger(level)
 	if err != nil {
 		return fmt.Errorf("could not create file logger: %w", err)
@@ -35,7 +43,10 @@ func Init() error {
 	setup = true
 
 	if level == "debug" {
-		Warn("log - Init", slog.String("warn", "debug logging enabled, might leak sensitive data"))
+		Warn(
+			"log - Init",
+			slog.String("warn", "debug logging enabled, secrets are redacted but file names and links are logged"),
+		)
 	}
 
 	wg.Wait()
@@ -85,32 +96,42 @@ func Error(msg string, args ...any) {
 	consoleLogger.Error(msg, args...)
 }
 
-func RemoveLogsDir() error {
-	err := os.RemoveAll(logsDir)
+// RemoveLogs deletes the log files of minls,
+// other files in the logs dir are left alone.
+func RemoveLogs() error {
+	files, err := filepath.Glob(filepath.Join(logsDir, logFilePattern))
 	if err != nil {
-		return fmt.Errorf("could not os.RemoveAll: %w", err)
+		return fmt.Errorf("could not find log files: %w", err)
+	}
+
+	for _, f := range files {
+		err = os.Remove(f)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("could not remove %s: %w", f, err)
+		}
 	}
 
 	return nil
 }
 
+// logFilePattern matches the names of the log files created by createFileLogger.
+const logFilePattern = "minls_*.log.json"
+
 var setup bool
 
-var logsDir = "logs"
+var logsDir string
 
 func createLogsDirIfNotExist() error {
-	exe, err := os.Executable()
+	var err error
+
+	logsDir, err = paths.Logs()
 	if err != nil {
-		return fmt.Errorf("could not find executeable: %w", err)
+		return fmt.Errorf("could not find logs dir: %w", err)
 	}
 
-	logsDir = filepath.Join(filepath.Dir(exe), logsDir)
-
-	err = os.Mkdir(logsDir, 0750)
+	err = os.MkdirAll(logsDir, 0750)
 	if err != nil {
-		if !os.IsExist(err) {
-			return fmt.Errorf("could not create logs dir: %w", err)
-		}
+		return fmt.Errorf("could not create logs dir: %w", err)
 	}
 
 	return nil
@@ -127,9 +148,9 @@ func createFileLogger(level string) error {
 		return fmt.Errorf("could not create log file: %w", err)
 	}
 
-	fileLogger = slog.New(slog.NewJSONHandler(logFile, &slog.HandlerOptions{
+	fileLogger = slog.New(newRedactHandler(slog.NewJSONHandler(logFile, &slog.HandlerOptions{
 		Level: slogLevelFromString(level),
-	}))
+	})))
 
 	return nil
 }
@@ -137,9 +158,9 @@ func createFileLogger(level string) error {
 var consoleLogger *slog.Logger
 
 func createConsoleLogger(level string) {
-	consoleLogger = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+	consoleLogger = slog.New(newRedactHandler(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
 		Level: slogLevelFromString(level),
-	}))
+	})))
 }
 
 func slogLevelFromString(s string) slog.Level {
@@ -185,8 +206,8 @@ func cleanOldLogs(wg *sync.WaitGroup) {
 			continue
 		}
 
-		split := strings.SplitN(fp, ".", splitFileNameSubstringCount)
-		if split[1] != "log.json" {
+		split := strings.SplitN(entry.Name(), ".", splitFileNameSubstringCount)
+		if len(split) != splitFileNameSubstringCount || split[1] != "log.json" {
 			fmt.Printf("log - cleanOldLogs: found unexpected file: '%s'\n", fp)
 			continue
 		}
@@ -0,0 +1,286 @@
+package log
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Mask replaces redacted values.
+const Mask = "********"
+
+// EnvRedactPatterns holds additional comma separated regular expressions,
+// matches are masked in all log output.
+const EnvRedactPatterns = "LOG_REDACT_PATTERNS"
+
+// secretKeySuffixes mark attributes (and keys of logged maps / structs)
+// whose values are always masked, compared lower case.
+var secretKeySuffixes = []string{
+	"secret",
+	"password",
+	"passwd",
+	"signature",
+	"api_key",
+	"apikey",
+	"token",
+	"authorization",
+}
+
+// secretQuery matches secret query or form parameters, e.g. the
+// X-Amz-Signature of presigned links or the signature sent to YOURLS.
+var secretQuery = regexp.MustCompile(
+	`(?i)((?:^|[?&\s])(?:x-amz-signature|x-amz-security-token|signature|password|api_key|apikey|token)=)[^&\s"']*`,
+)
+
+var (
+	secretsMu sync.RWMutex
+	// secretValues are masked wherever they appear, see AddSecret.
+	secretValues = make(map[string]bool)
+	patterns     []*regexp.Regexp
+)
+
+// minSecretLength avoids masking every occurrence of short values.
+const minSecretLength = 4
+
+// AddSecret masks the value wherever it appears in log output,
+// e.g. the configured credentials. Short values are ignored.
+func AddSecret(value string) {
+	if len(value) < minSecretLength {
+		return
+	}
+
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+
+	secretValues[value] = true
+}
+
+// loadRedactPatterns compiles EnvRedactPatterns.
+func loadRedactPatterns() error {
+	compiled := make([]*regexp.Regexp, 0)
+
+	for p := range strings.SplitSeq(os.Getenv(EnvRedactPatterns), ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return fmt.Errorf("could not compile %s pattern %q: %w", EnvRedactPatterns, p, err)
+		}
+
+		compiled = append(compiled, re)
+	}
+
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+
+	patterns = compiled
+
+	return nil
+}
+
+// isSecretKey reports whether values of the key are always masked.
+func isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range secretKeySuffixes {
+		if strings.HasSuffix(key, s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// redactString masks secret query parameters, registered secrets and pattern matches.
+func redactString(s string) string {
+	s = secretQuery.ReplaceAllString(s, "${1}"+Mask)
+
+	secretsMu.RLock()
+	defer secretsMu.RUnlock()
+
+	for v := range secretValues {
+		s = strings.ReplaceAll(s, v, Mask)
+	}
+
+	for _, re := range patterns {
+		s = re.ReplaceAllString(s, Mask)
+	}
+
+	return s
+}
+
+// redactHandler masks secrets in the attributes before passing
+// records to the wrapped handler.
+type redactHandler struct {
+	next slog.Handler
+}
+
+func newRedactHandler(next slog.Handler) *redactHandler {
+	return &redactHandler{next: next}
+}
+
+func (h *redactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *redactHandler) Handle(ctx context.Context, r slog.Record) error {
+	redacted := slog.NewRecord(r.Time, r.Level, redactString(r.Message), r.PC)
+
+	r.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(redactAttr(a))
+		return true
+	})
+
+	return h.next.Handle(ctx, redacted)
+}
+
+func (h *redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		redacted = append(redacted, redactAttr(a))
+	}
+
+	return &redactHandler{next: h.next.WithAttrs(redacted)}
+}
+
+func (h *redactHandler) WithGroup(name string) slog.Handler {
+	return &redactHandler{next: h.next.WithGroup(name)}
+}
+
+func redactAttr(a slog.Attr) slog.Attr {
+	// resolves slog.LogValuer, e.g. env.Env masking its secret fields
+	v := a.Value.Resolve()
+
+	switch {
+	case v.Kind() == slog.KindGroup:
+		attrs := v.Group()
+		redacted := make([]slog.Attr, 0, len(attrs))
+		for _, ga := range attrs {
+			redacted = append(redacted, redactAttr(ga))
+		}
+
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(redacted...)}
+	case isSecretKey(a.Key):
+		if v.Kind() == slog.KindString && v.String() == "" {
+			return slog.Attr{Key: a.Key, Value: v}
+		}
+
+		return slog.String(a.Key, Mask)
+	case v.Kind() == slog.KindString:
+		return slog.String(a.Key, redactString(v.String()))
+	case v.Kind() == slog.KindAny:
+		return slog.Any(a.Key, redactAny(v.Any()))
+	default:
+		return slog.Attr{Key: a.Key, Value: v}
+	}
+}
+
+// redactAny masks secrets in arbitrary values. Maps and structs with
+// exported fields (and slices of them) are converted to their JSON
+// representation so keys and fields can be checked with isSecretKey,
+// everything else is masked in its printed form.
+func redactAny(v any) any {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case error:
+		return redactString(v.Error())
+	case *url.URL:
+		if v == nil {
+			return nil
+		}
+
+		return redactString(v.String())
+	}
+
+	if !hasFields(reflect.TypeOf(v)) {
+		return redactString(fmt.Sprintf("%+v", v))
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return redactString(fmt.Sprintf("%+v", v))
+	}
+
+	var generic any
+
+	err = json.Unmarshal(b, &generic)
+	if err != nil {
+		return redactString(fmt.Sprintf("%+v", v))
+	}
+
+	return redactJSON(generic)
+}
+
+// hasFields reports whether values of t have keys or exported fields
+// which JSON shows, e.g. not time.Time or time.Duration.
+func hasFields(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Map:
+		return true
+	case reflect.Slice, reflect.Array:
+		return hasFields(t.Elem())
+	case reflect.Struct:
+		for i := range t.NumField() {
+			if t.Field(i).IsExported() {
+				return true
+			}
+		}
+
+		return false
+	default:
+		return false
+	}
+}
+
+func redactJSON(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, value := range v {
+			if isSecretKey(k) && !isEmptyJSON(value) {
+				v[k] = Mask
+				continue
+			}
+
+			v[k] = redactJSON(value)
+		}
+
+		return v
+	case []any:
+		for i, value := range v {
+			v[i] = redactJSON(value)
+		}
+
+		return v
+	case string:
+		return redactString(v)
+	default:
+		return v
+	}
+}
+
+func isEmptyJSON(v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []any:
+		return len(v) == 0 || (len(v) == 1 && v[0] == "")
+	default:
+		return false
+	}
+}
@@ -0,0 +1,130 @@
+package log
+
+import (
+	"errors"
+	"log/slog"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type redactFields struct {
+	Name        string `json:"name"`
+	AccessKey   string `json:"access_key"`
+	AccessToken string `json:"access_token"`
+}
+
+type redactUnexported struct {
+	password string
+}
+
+func (r redactUnexported) String() string {
+	return "unexported " + r.password
+}
+
+type redactValuer struct{}
+
+func (redactValuer) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("password", "hunter2"), slog.String("user", "bob"))
+}
+
+func TestRedactAttr(t *testing.T) {
+	AddSecret("registered-secret")
+
+	link, err := url.Parse("https://minio/bucket/key?X-Amz-Signature=abcdef&X-Amz-Expires=3600")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		attr slog.Attr
+		want slog.Value
+	}{
+		{
+			name: "secret key",
+			attr: slog.String("yourls_signature", "abc"),
+			want: slog.StringValue(Mask),
+		},
+		{
+			name: "empty secret key",
+			attr: slog.String("password", ""),
+			want: slog.StringValue(""),
+		},
+		{
+			name: "secret key with non string value",
+			attr: slog.Int("token", 42),
+			want: slog.StringValue(Mask),
+		},
+		{
+			name: "query parameter",
+			attr: slog.String("link", "https://sho.rt/api?signature=abc&action=stats"),
+			want: slog.StringValue("https://sho.rt/api?signature=" + Mask + "&action=stats"),
+		},
+		{
+			name: "registered secret",
+			attr: slog.String("msg", "failed with registered-secret"),
+			want: slog.StringValue("failed with " + Mask),
+		},
+		{
+			name: "plain value",
+			attr: slog.Int("count", 3),
+			want: slog.IntValue(3),
+		},
+		{
+			name: "url",
+			attr: slog.Any("url", link),
+			want: slog.AnyValue("https://minio/bucket/key?X-Amz-Signature=" + Mask + "&X-Amz-Expires=3600"),
+		},
+		{
+			name: "error",
+			attr: slog.Any("err", errors.New("bad password=abc")),
+			want: slog.AnyValue("bad password=" + Mask),
+		},
+		{
+			name: "map",
+			attr: slog.Any("values", map[string]string{"api_key": "abc", "user": "bob"}),
+			want: slog.AnyValue(map[string]any{"api_key": Mask, "user": "bob"}),
+		},
+		{
+			name: "struct",
+			attr: slog.Any("cfg", &redactFields{Name: "registered-secret", AccessKey: "key", AccessToken: "tok"}),
+			want: slog.AnyValue(map[string]any{"name": Mask, "access_key": "key", "access_token": Mask}),
+		},
+		{
+			name: "struct without exported fields",
+			attr: slog.Any("v", redactUnexported{password: "registered-secret"}),
+			want: slog.AnyValue("unexported " + Mask),
+		},
+		{
+			name: "duration",
+			attr: slog.Any("v", []time.Duration{time.Hour}),
+			want: slog.AnyValue("[1h0m0s]"),
+		},
+		{
+			name: "time",
+			attr: slog.Any("v", time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)),
+			want: slog.TimeValue(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)),
+		},
+		{
+			name: "log valuer",
+			attr: slog.Any("env", redactValuer{}),
+			want: slog.GroupValue(slog.String("password", Mask), slog.String("user", "bob")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := redactAttr(tt.attr)
+
+			if got.Key != tt.attr.Key {
+				t.Errorf("key = %q, want %q", got.Key, tt.attr.Key)
+			}
+
+			if !reflect.DeepEqual(got.Value.Any(), tt.want.Any()) {
+				t.Errorf("value = %#v, want %#v", got.Value.Any(), tt.want.Any())
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package minio
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/minio/minio-go/v7"
+
+	"github.com/devusSs/minls/internal/log"
+)
+
+// ListBuckets returns the names of all buckets the credentials can see,
+// it is the cheapest call to check endpoint and credentials.
+func (c *Client) ListBuckets(ctx context.Context) ([]string, error) {
+	if ctx == nil {
+		return nil, errors.New("context cannot be nil")
+	}
+
+	buckets, err := c.client.ListBuckets(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not list buckets: %w", err)
+	}
+
+	names := make([]string, 0, len(buckets))
+	for _, b := range buckets {
+		names = append(names, b.Name)
+	}
+
+	log.Debug("minio - *client.ListBuckets", slog.Int("buckets", len(names)))
+
+	return names, nil
+}
+
+// CreateBuckets creates the public and private bucket if they do not exist,
+// the public bucket gets the public read policy.
+func (c *Client) CreateBuckets(ctx context.Context) error {
+	for _, public := range []bool{true, false} {
+		err := c.createBucket(ctx, public)
+		if err != nil {
+			return fmt.Errorf("bucket %s: %w", c.Bucket(public), err)
+		}
+	}
+
+	return nil
+}
+
+// BucketExists reports whether the bucket exists.
+func (c *Client) BucketExists(ctx context.Context, bucket string) (bool, error) {
+	if ctx == nil {
+		return false, errors.New("context cannot be nil")
+	}
+
+	return c.client.BucketExists(ctx, bucket)
+}
+
+// ReservedPrefix is the key prefix of objects minls writes for itself
+// (e.g. by Probe), they are never uploads.
+const ReservedPrefix = ".minls/"
+
+// IsReserved reports whether key is below ReservedPrefix.
+func IsReserved(key string) bool {
+	return strings.HasPrefix(key, ReservedPrefix)
+}
+
+// Probe writes, reads back and deletes a small object
+// to check the permissions on the bucket. The object has a unique key
+// below ReservedPrefix so concurrent probes do not collide.
+func (c *Client) Probe(ctx context.Context, bucket string) error {
+	if ctx == nil {
+		return errors.New("context cannot be nil")
+	}
+
+	uid, err := uuid.NewRandom()
+	if err != nil {
+		return fmt.Errorf("could not create uuid: %w", err)
+	}
+
+	probeKey := ReservedPrefix + "probe-" + uid.String()
+	content := []byte("minls probe")
+
+	_, err = c.client.PutObject(
+		ctx,
+		bucket,
+		probeKey,
+		bytes.NewReader(content),
+		int64(len(content)),
+		minio.PutObjectOptions{ContentType: "text/plain"},
+	)
+	if err != nil {
+		return fmt.Errorf("could not put probe object: %w", err)
+	}
+
+	obj, err := c.client.GetObject(ctx, bucket, probeKey, minio.GetObjectOptions{})
+	if err != nil {
+		return fmt.Errorf("could not get probe object: %w", err)
+	}
+
+	got, err := io.ReadAll(obj)
+	obj.Close()
+
+	if err != nil {
+		return fmt.Errorf("could not read probe object: %w", err)
+	}
+
+	if !bytes.Equal(got, content) {
+		return errors.New("probe object was read back with different content")
+	}
+
+	err = c.client.RemoveObject(ctx, bucket, probeKey, minio.RemoveObjectOptions{})
+	if err != nil {
+		return fmt.Errorf("could not remove probe object: %w", err)
+	}
+
+	log.Debug("minio - *client.Probe", slog.String("bucket", bucket), slog.String("info", "put, get and remove work"))
+
+	return nil
+}
+
+// HasPublicPolicy reports whether the public bucket allows anonymous reads
+// of its objects, which public links rely on.
+func (c *Client) HasPublicPolicy(ctx context.Context) (bool, error) {
+	if ctx == nil {
+		return false, errors.New("context cannot be nil")
+	}
+
+	raw, err := c.client.GetBucketPolicy(ctx, c.publicBucket)
+	if err != nil {
+		return false, fmt.Errorf("could not get bucket policy: %w", err)
+	}
+
+	if raw == "" {
+		return false, nil
+	}
+
+	policy := &bucketPolicy{}
+
+	err = json.Unmarshal([]byte(raw), policy)
+	if err != nil {
+		return false, fmt.Errorf("could not parse bucket policy: %w", err)
+	}
+
+	return policy.allowsAnonymousGet(), nil
+}
+
+type bucketPolicy struct {
+	Statement []struct {
+		Effect    string          `json:"Effect"`
+		Principal json.RawMessage `json:"Principal"`
+		Action    stringOrList    `json:"Action"`
+	} `json:"Statement"`
+}
+
+func (p *bucketPolicy) allowsAnonymousGet() bool {
+	for _, st := range p.Statement {
+		if st.Effect != "Allow" || !anonymousPrincipal(st.Principal) {
+			continue
+		}
+
+		if slices.Contains(st.Action, "s3:GetObject") || slices.Contains(st.Action, "s3:*") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// anonymousPrincipal reports whether the principal is "*" or {"AWS": "*"} / {"AWS": ["*"]}.
+func anonymousPrincipal(raw json.RawMessage) bool {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s == "*"
+	}
+
+	var p struct {
+		AWS stringOrList `json:"AWS"`
+	}
+
+	return json.Unmarshal(raw, &p) == nil && slices.Contains(p.AWS, "*")
+}
+
+// stringOrList is a policy field which is either a string or a list of strings.
+type stringOrList []string
+
+func (l *stringOrList) UnmarshalJSON(b []byte) error {
+	var s string
+	if json.Unmarshal(b, &s) == nil {
+		*l = []string{s}
+		return nil
+	}
+
+	var list []string
+
+	err := json.Unmarshal(b, &list)
+	if err != nil {
+		return err
+	}
+
+	*l = list
+
+	return nil
+}
@@ -1,31 +1,119 @@
 package minio
 
 import (
+	"cmp"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
 	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/minio/minio-go/v7"
-	"github.com/minio/minio-go/v7/pkg/credentials"
 
 	"github.com/devusSs/minls/internal/log"
 )
 
 type Client struct {
 	client *minio.Client
+	// pathPrefix is the subpath MinIO is served on behind a reverse proxy.
+	pathPrefix string
+	// virtualHost is set if buckets are addressed as subdomains.
+	virtualHost   bool
+	publicBucket  string
+	privateBucket string
+	linkExpiry    time.Duration
 }
 
-func NewClient(
-	accessKey string,
-	accessSecret string,
-	endpoint string,
-) (*Client, error) {
-	secure := strings.Contains(endpoint, "https://")
-	endpoint = strings.Replace(endpoint, "https://", "", 1)
-	endpoint = strings.Replace(endpoint, "http://", "", 1)
+// Config configures the connection to MinIO.
+type Config struct {
+	// Endpoint is the URL of the MinIO server, it may contain a subpath
+	// if MinIO sits behind a reverse proxy (e.g. https://example.com/minio).
+	Endpoint     string
+	AccessKey    string
+	AccessSecret string
+	// CABundle is the path to a PEM file with additional trusted CAs.
+	CABundle string
+	// ClientCert and ClientKey are paths to a PEM client certificate
+	// and its key used for mTLS.
+	ClientCert string
+	ClientKey  string
+	// InsecureSkipVerify disables TLS certificate verification, lab use only.
+	InsecureSkipVerify bool
+	// BucketLookup is one of the BucketLookup* constants.
+	BucketLookup string
+	// Credentials selects the credentials source,
+	// nil uses AccessKey and AccessSecret.
+	Credentials *CredentialsConfig
+	// PublicBucket and PrivateBucket default to DefaultBucketPublic
+	// and DefaultBucketPrivate if empty.
+	PublicBucket  string
+	PrivateBucket string
+	// LinkExpiry is how long presigned links are valid,
+	// zero uses DefaultLinkExpiry. S3 allows at most MaxLinkExpiry.
+	LinkExpiry time.Duration
+}
+
+const (
+	BucketLookupAuto = "auto"
+	BucketLookupPath = "path"
+	BucketLookupDNS  = "dns"
+)
+
+func NewClient(cfg *Config) (*Client, error) {
+	if cfg == nil {
+		return nil, errors.New("nil config")
+	}
+
+	endpoint, err := parseEndpoint(cfg.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse endpoint: %w", err)
+	}
+
+	secure := endpoint.Scheme == "https"
+	pathPrefix := strings.TrimSuffix(endpoint.Path, "/")
+
+	log.Debug(
+		"minio - NewClient",
+		slog.String("action", "parse_endpoint"),
+		slog.String("host", endpoint.Host),
+		slog.Bool("secure", secure),
+		slog.String("path_prefix", pathPrefix),
+	)
+
+	if cfg.LinkExpiry < 0 || cfg.LinkExpiry > MaxLinkExpiry {
+		return nil, fmt.Errorf("link expiry must be between 1s and %s, got %s", MaxLinkExpiry, cfg.LinkExpiry)
+	}
+
+	lookup, err := bucketLookupType(cfg.BucketLookup)
+	if err != nil {
+		return nil, err
+	}
 
-	c, err := minio.New(endpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(accessKey, accessSecret, ""),
-		Secure: secure,
+	transport, err := newTransport(cfg, secure)
+	if err != nil {
+		return nil, fmt.Errorf("could not create transport: %w", err)
+	}
+
+	var rt http.RoundTripper = transport
+	if pathPrefix != "" {
+		rt = &pathPrefixTransport{prefix: pathPrefix, next: transport}
+	}
+
+	creds, err := newCredentials(cfg, endpoint.String(), &http.Client{Transport: transport})
+	if err != nil {
+		return nil, fmt.Errorf("could not create credentials: %w", err)
+	}
+
+	c, err := minio.New(endpoint.Host, &minio.Options{
+		Creds:        creds,
+		Secure:       secure,
+		Transport:    rt,
+		BucketLookup: lookup,
 	})
 	if err != nil {
 		return nil, err
@@ -36,6 +124,170 @@ func NewClient(
 	}
 
 	return &Client{
-		client: c,
+		client:        c,
+		pathPrefix:    pathPrefix,
+		virtualHost:   lookup == minio.BucketLookupDNS,
+		publicBucket:  cmp.Or(cfg.PublicBucket, DefaultBucketPublic),
+		privateBucket: cmp.Or(cfg.PrivateBucket, DefaultBucketPrivate),
+		linkExpiry:    cmp.Or(cfg.LinkExpiry, DefaultLinkExpiry),
 	}, nil
 }
+
+// Bucket returns the name of the public or private bucket.
+func (c *Client) Bucket(public bool) string {
+	if public {
+		return c.publicBucket
+	}
+
+	return c.privateBucket
+}
+
+func parseEndpoint(endpoint string) (*url.URL, error) {
+	if !strings.Contains(endpoint, "://") {
+		log.Warn(
+			"minio - parseEndpoint",
+			slog.String("warn", "endpoint does not contain schema, adding 'http://'"),
+		)
+		endpoint = "http://" + endpoint
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported scheme: %s", u.Scheme)
+	}
+
+	if u.Host == "" {
+		return nil, errors.New("endpoint does not contain a host")
+	}
+
+	if u.RawQuery != "" || u.Fragment != "" {
+		return nil, errors.New("endpoint must not contain a query or fragment")
+	}
+
+	return u, nil
+}
+
+func bucketLookupType(s string) (minio.BucketLookupType, error) {
+	switch s {
+	case "", BucketLookupAuto:
+		return minio.BucketLookupAuto, nil
+	case BucketLookupPath:
+		return minio.BucketLookupPath, nil
+	case BucketLookupDNS:
+		return minio.BucketLookupDNS, nil
+	default:
+		return minio.BucketLookupAuto, fmt.Errorf("unknown bucket lookup: %s", s)
+	}
+}
+
+func newTransport(cfg *Config, secure bool) (*http.Transport, error) {
+	transport, err := minio.DefaultTransport(secure)
+	if err != nil {
+		return nil, err
+	}
+
+	if !secure {
+		return transport, nil
+	}
+
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	if cfg.CABundle != "" {
+		var pool *x509.CertPool
+		pool, err = x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+
+		var pem []byte
+		pem, err = os.ReadFile(cfg.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("could not read ca bundle: %w", err)
+		}
+
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in ca bundle %s", cfg.CABundle)
+		}
+
+		transport.TLSClientConfig.RootCAs = pool
+	}
+
+	if cfg.ClientCert != "" || cfg.ClientKey != "" {
+		var cert tls.Certificate
+		cert, err = tls.LoadX509KeyPair(cfg.ClientCert, cfg.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("could not load client certificate: %w", err)
+		}
+
+		transport.TLSClientConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if cfg.InsecureSkipVerify {
+		log.Warn(
+			"minio - newTransport",
+			slog.String(
+				"warn",
+				"!!! TLS CERTIFICATE VERIFICATION DISABLED, CONNECTIONS CAN BE INTERCEPTED, USE FOR LAB SETUPS ONLY !!!",
+			),
+		)
+		transport.TLSClientConfig.InsecureSkipVerify = true //nolint:gosec // explicitly configured by the user
+	}
+
+	return transport, nil
+}
+
+// pathPrefixTransport prepends the path prefix to every request
+// for MinIO instances served on a subpath behind a reverse proxy.
+// Requests are signed without the prefix, so the reverse proxy has to strip it
+// before forwarding the request to MinIO.
+type pathPrefixTransport struct {
+	prefix string
+	next   http.RoundTripper
+}
+
+func (t *pathPrefixTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := req.Clone(req.Context())
+	r.URL.Path = t.prefix + r.URL.Path
+
+	if r.URL.RawPath != "" {
+		r.URL.RawPath = t.prefix + r.URL.RawPath
+	}
+
+	return t.next.RoundTrip(r)
+}
+
+// objectURL returns the (unsigned) URL of an object.
+func (c *Client) objectURL(bucket string, key string) *url.URL {
+	u := *c.client.EndpointURL()
+
+	if c.virtualHost {
+		u.Host = bucket + "." + u.Host
+		u.Path = "/" + key
+	} else {
+		u.Path = "/" + bucket + "/" + key
+	}
+
+	return c.withPathPrefix(&u)
+}
+
+// withPathPrefix adds the path prefix to a link generated by minio-go.
+func (c *Client) withPathPrefix(u *url.URL) *url.URL {
+	if c.pathPrefix == "" {
+		return u
+	}
+
+	prefixed := *u
+	prefixed.Path = c.pathPrefix + u.Path
+
+	if u.RawPath != "" {
+		prefixed.RawPath = c.pathPrefix + u.RawPath
+	}
+
+	return &prefixed
+}
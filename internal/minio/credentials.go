@@ -0,0 +1,189 @@
+package minio
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/minio/minio-go/v7/pkg/credentials"
+
+	"github.com/devusSs/minls/internal/log"
+)
+
+const (
+	// CredentialsStatic uses Config.AccessKey and Config.AccessSecret.
+	CredentialsStatic = "static"
+	// CredentialsEnv uses the MINIO_ACCESS_KEY with MINIO_ACCESS_SECRET (the names
+	// minls uses), MINIO_ROOT_USER / MINIO_ROOT_PASSWORD or MINIO_ACCESS_KEY with
+	// MINIO_SECRET_KEY (the names of the minio tools) or AWS_ACCESS_KEY_ID /
+	// AWS_SECRET_ACCESS_KEY environment variables.
+	CredentialsEnv = "env"
+	// CredentialsMC uses an alias of the mc config file (~/.mc/config.json).
+	CredentialsMC = "mc"
+	// CredentialsAWS uses a profile of an AWS shared credentials file (~/.aws/credentials).
+	CredentialsAWS = "aws"
+	// CredentialsSTS requests short-lived credentials via STS AssumeRole
+	// using Config.AccessKey and Config.AccessSecret.
+	CredentialsSTS = "sts"
+	// CredentialsChain tries env (except the minls names), mc, aws and static credentials in that order.
+	CredentialsChain = "chain"
+)
+
+// CredentialsConfig configures where credentials are loaded from.
+// Empty file paths, aliases and profiles use the defaults of minio-go.
+type CredentialsConfig struct {
+	// Source is one of the Credentials* constants, defaults to CredentialsStatic.
+	Source             string
+	MCConfigFile       string
+	MCAlias            string
+	AWSCredentialsFile string
+	AWSProfile         string
+	// STSEndpoint defaults to the MinIO endpoint.
+	STSEndpoint string
+	STSRoleARN  string
+	// STSDuration defaults to one hour.
+	STSDuration time.Duration
+	// Getenv looks up the variables of the env source, defaults to os.Getenv.
+	// It lets the variables come from the resolved config of a profile.
+	Getenv func(key string) string
+}
+
+// RequiresStaticKeys reports whether source needs an access key and secret.
+func RequiresStaticKeys(source string) bool {
+	return source == "" || source == CredentialsStatic || source == CredentialsSTS
+}
+
+func newCredentials(
+	cfg *Config,
+	endpoint string,
+	client *http.Client,
+) (*credentials.Credentials, error) {
+	cc := cfg.Credentials
+	if cc == nil {
+		cc = &CredentialsConfig{}
+	}
+
+	getenv := cc.Getenv
+	if getenv == nil {
+		getenv = os.Getenv
+	}
+
+	log.Debug("minio - newCredentials", slog.String("source", cc.Source))
+
+	switch cc.Source {
+	case "", CredentialsStatic:
+		return credentials.NewStaticV4(cfg.AccessKey, cfg.AccessSecret, ""), nil
+	case CredentialsEnv:
+		return credentials.NewChainCredentials([]credentials.Provider{
+			&envMinls{getenv: getenv, pairs: slices.Concat(minlsEnvPairs, minioEnvPairs)},
+			&credentials.EnvAWS{},
+		}), nil
+	case CredentialsMC:
+		return credentials.NewFileMinioClient(cc.MCConfigFile, cc.MCAlias), nil
+	case CredentialsAWS:
+		return credentials.NewFileAWSCredentials(cc.AWSCredentialsFile, cc.AWSProfile), nil
+	case CredentialsSTS:
+		stsEndpoint := cc.STSEndpoint
+		if stsEndpoint == "" {
+			stsEndpoint = endpoint
+		}
+
+		log.Debug("minio - newCredentials", slog.String("sts_endpoint", stsEndpoint))
+
+		if cfg.AccessKey == "" || cfg.AccessSecret == "" {
+			return nil, errors.New("sts assume role requires an access key and secret")
+		}
+
+		return credentials.New(&credentials.STSAssumeRole{
+			Client:      client,
+			STSEndpoint: stsEndpoint,
+			Options: credentials.STSAssumeRoleOptions{
+				AccessKey:       cfg.AccessKey,
+				SecretKey:       cfg.AccessSecret,
+				RoleARN:         cc.STSRoleARN,
+				DurationSeconds: int(cc.STSDuration.Seconds()),
+			},
+		}), nil
+	case CredentialsChain:
+		// the minls names are the static keys and so come last
+		providers := []credentials.Provider{
+			&envMinls{getenv: getenv, pairs: minioEnvPairs},
+			&credentials.EnvAWS{},
+			&credentials.FileMinioClient{Filename: cc.MCConfigFile, Alias: cc.MCAlias},
+			&credentials.FileAWSCredentials{Filename: cc.AWSCredentialsFile, Profile: cc.AWSProfile},
+		}
+
+		if cfg.AccessKey != "" && cfg.AccessSecret != "" {
+			providers = append(providers, &credentials.Static{
+				Value: credentials.Value{
+					AccessKeyID:     cfg.AccessKey,
+					SecretAccessKey: cfg.AccessSecret,
+					SignerType:      credentials.SignatureV4,
+				},
+			})
+		}
+
+		return credentials.NewChainCredentials(providers), nil
+	default:
+		return nil, fmt.Errorf("unknown credentials source: %s", cc.Source)
+	}
+}
+
+// envPair is the pair of environment variables holding an access key and its secret.
+type envPair struct {
+	key    string
+	secret string
+}
+
+var (
+	// minlsEnvPairs are the variables of minio.access_key and minio.access_secret.
+	minlsEnvPairs = []envPair{{key: "MINIO_ACCESS_KEY", secret: "MINIO_ACCESS_SECRET"}}
+	// minioEnvPairs are the variables of the minio server and tools.
+	minioEnvPairs = []envPair{
+		{key: "MINIO_ROOT_USER", secret: "MINIO_ROOT_PASSWORD"},
+		{key: "MINIO_ACCESS_KEY", secret: "MINIO_SECRET_KEY"},
+	}
+)
+
+// envMinls retrieves credentials from the first pair of variables which
+// are both set. Unlike credentials.EnvMinio it looks them up with getenv,
+// so they come from the profile and not from the process environment.
+type envMinls struct {
+	getenv    func(key string) string
+	pairs     []envPair
+	retrieved bool
+}
+
+func (e *envMinls) Retrieve() (credentials.Value, error) {
+	e.retrieved = true
+
+	for _, p := range e.pairs {
+		id := e.getenv(p.key)
+		secret := e.getenv(p.secret)
+
+		if id == "" || secret == "" {
+			continue
+		}
+
+		return credentials.Value{
+			AccessKeyID:     id,
+			SecretAccessKey: secret,
+			SignerType:      credentials.SignatureV4,
+		}, nil
+	}
+
+	// empty values let the chain try the next provider
+	return credentials.Value{SignerType: credentials.SignatureAnonymous}, nil
+}
+
+func (e *envMinls) RetrieveWithCredContext(_ *credentials.CredContext) (credentials.Value, error) {
+	return e.Retrieve()
+}
+
+func (e *envMinls) IsExpired() bool {
+	return !e.retrieved
+}
@@ -0,0 +1,99 @@
+package minio
+
+import (
+	"testing"
+)
+
+func TestEnvCredentials(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		process map[string]string
+		key     string
+		secret  string
+	}{
+		{
+			name:   "minls names",
+			env:    map[string]string{"MINIO_ACCESS_KEY": "key", "MINIO_ACCESS_SECRET": "secret"},
+			key:    "key",
+			secret: "secret",
+		},
+		{
+			name:   "minio names",
+			env:    map[string]string{"MINIO_ACCESS_KEY": "key", "MINIO_SECRET_KEY": "secret"},
+			key:    "key",
+			secret: "secret",
+		},
+		{
+			name:   "root names",
+			env:    map[string]string{"MINIO_ROOT_USER": "root", "MINIO_ROOT_PASSWORD": "secret"},
+			key:    "root",
+			secret: "secret",
+		},
+		{
+			name:    "aws names",
+			process: map[string]string{"AWS_ACCESS_KEY_ID": "aws-key", "AWS_SECRET_ACCESS_KEY": "aws-secret"},
+			key:     "aws-key",
+			secret:  "aws-secret",
+		},
+		{
+			name:    "profile beats process",
+			env:     map[string]string{"MINIO_ACCESS_KEY": "key", "MINIO_ACCESS_SECRET": "secret"},
+			process: map[string]string{"MINIO_ACCESS_KEY": "other", "MINIO_ACCESS_SECRET": "other"},
+			key:     "key",
+			secret:  "secret",
+		},
+	}
+
+	vars := []string{
+		"MINIO_ACCESS_KEY",
+		"MINIO_ACCESS_SECRET",
+		"MINIO_SECRET_KEY",
+		"MINIO_ROOT_USER",
+		"MINIO_ROOT_PASSWORD",
+		"AWS_ACCESS_KEY_ID",
+		"AWS_ACCESS_KEY",
+		"AWS_SECRET_ACCESS_KEY",
+		"AWS_SECRET_KEY",
+		"AWS_SESSION_TOKEN",
+	}
+
+	for _, source := range []string{CredentialsEnv, CredentialsChain} {
+		for _, tt := range tests {
+			t.Run(source+" "+tt.name, func(t *testing.T) {
+				for _, v := range vars {
+					t.Setenv(v, tt.process[v])
+				}
+
+				getenv := func(key string) string {
+					return tt.env[key]
+				}
+
+				cfg := &Config{
+					AccessKey:    getenv("MINIO_ACCESS_KEY"),
+					AccessSecret: getenv("MINIO_ACCESS_SECRET"),
+					Credentials: &CredentialsConfig{
+						Source:             source,
+						MCConfigFile:       t.TempDir() + "/missing.json",
+						AWSCredentialsFile: t.TempDir() + "/missing",
+						Getenv:             getenv,
+					},
+				}
+
+				creds, err := newCredentials(cfg, "http://localhost:9000", nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				v, err := creds.Get()
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if v.AccessKeyID != tt.key || v.SecretAccessKey != tt.secret {
+					t.Errorf("got %q / %q, want %q / %q", v.AccessKeyID, v.SecretAccessKey, tt.key, tt.secret)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package minio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+
+	"github.com/devusSs/minls/internal/log"
+)
+
+// ObjectInfo contains information about an uploaded object.
+type ObjectInfo struct {
+	Bucket       string            `json:"bucket"`
+	Key          string            `json:"key"`
+	Size         int64             `json:"size"`
+	ETag         string            `json:"etag"`
+	ContentType  string            `json:"content_type"`
+	LastModified time.Time         `json:"last_modified"`
+	UserMetadata map[string]string `json:"user_metadata,omitempty"`
+	// RetentionMode, RetainUntil and LegalHold are empty
+	// if object locking is not enabled for the bucket.
+	RetentionMode string    `json:"retention_mode,omitempty"`
+	RetainUntil   time.Time `json:"retain_until,omitzero"`
+	LegalHold     string    `json:"legal_hold,omitempty"`
+}
+
+// StatObject returns information about the object in the bucket.
+func (c *Client) StatObject(ctx context.Context, bucket string, key string) (*ObjectInfo, error) {
+	if ctx == nil {
+		return nil, errors.New("context cannot be nil")
+	}
+
+	info, err := c.client.StatObject(ctx, bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("could not stat object: %w", err)
+	}
+
+	log.Debug(
+		"minio - *client.StatObject",
+		slog.String("bucket", bucket),
+		slog.String("key", key),
+		slog.Any("info", info),
+	)
+
+	oi := &ObjectInfo{
+		Bucket:       bucket,
+		Key:          info.Key,
+		Size:         info.Size,
+		ETag:         info.ETag,
+		ContentType:  info.ContentType,
+		LastModified: info.LastModified,
+		UserMetadata: info.UserMetadata,
+	}
+
+	c.statLocking(ctx, oi)
+
+	return oi, nil
+}
+
+// statLocking adds retention and legal hold information to the object info.
+// Both fail if object locking is not enabled, which is not an error for us.
+func (c *Client) statLocking(ctx context.Context, oi *ObjectInfo) {
+	mode, until, err := c.client.GetObjectRetention(ctx, oi.Bucket, oi.Key, "")
+	if err != nil {
+		log.Debug("minio - *client.statLocking", slog.String("retention", "unavailable"), slog.Any("err", err))
+	} else {
+		if mode != nil {
+			oi.RetentionMode = string(*mode)
+		}
+
+		if until != nil {
+			oi.RetainUntil = *until
+		}
+	}
+
+	hold, err := c.client.GetObjectLegalHold(ctx, oi.Bucket, oi.Key, minio.GetObjectLegalHoldOptions{})
+	if err != nil {
+		log.Debug("minio - *client.statLocking", slog.String("legal_hold", "unavailable"), slog.Any("err", err))
+		return
+	}
+
+	if hold != nil {
+		oi.LegalHold = string(*hold)
+	}
+}
+
+// ListObjects returns the keys of all objects in the bucket,
+// missing buckets have no objects.
+func (c *Client) ListObjects(ctx context.Context, bucket string) ([]string, error) {
+	if ctx == nil {
+		return nil, errors.New("context cannot be nil")
+	}
+
+	exists, err := c.client.BucketExists(ctx, bucket)
+	if err != nil {
+		return nil, fmt.Errorf("could not check if bucket exists: %w", err)
+	}
+
+	keys := make([]string, 0)
+	if !exists {
+		return keys, nil
+	}
+
+	for obj := range c.client.ListObjects(ctx, bucket, minio.ListObjectsOptions{Recursive: true}) {
+		if obj.Err != nil {
+			return nil, fmt.Errorf("could not list objects: %w", obj.Err)
+		}
+
+		keys = append(keys, obj.Key)
+	}
+
+	log.Debug("minio - *client.ListObjects", slog.String("bucket", bucket), slog.Int("objects", len(keys)))
+
+	return keys, nil
+}
+
+// DownloadObject writes the object to the file at path.
+func (c *Client) DownloadObject(ctx context.Context, bucket string, key string, path string) error {
+	if ctx == nil {
+		return errors.New("context cannot be nil")
+	}
+
+	err := c.client.FGetObject(ctx, bucket, key, path, minio.GetObjectOptions{})
+	if err != nil {
+		return fmt.Errorf("could not fget object: %w", err)
+	}
+
+	log.Debug(
+		"minio - *client.DownloadObject",
+		slog.String("bucket", bucket),
+		slog.String("key", key),
+		slog.String("path", path),
+	)
+
+	return nil
+}
+
+// RemoveObject deletes the object, missing objects are no error.
+func (c *Client) RemoveObject(ctx context.Context, bucket string, key string) error {
+	if ctx == nil {
+		return errors.New("context cannot be nil")
+	}
+
+	err := c.client.RemoveObject(ctx, bucket, key, minio.RemoveObjectOptions{})
+	if err != nil {
+		return fmt.Errorf("could not remove object: %w", err)
+	}
+
+	log.Debug("minio - *client.RemoveObject", slog.String("bucket", bucket), slog.String("key", key))
+
+	return nil
+}
+
+// ObjectLocation is the bucket and key an object link points to.
+type ObjectLocation struct {
+	Bucket string `json:"bucket"`
+	Key    string `json:"key"`
+}
+
+// ParseLink parses a public or presigned link returned by UploadFile.
+// Both path style (<endpoint>/<bucket>/<key>) and virtual host style
+// (<bucket>.<endpoint>/<key>) links are supported.
+func (c *Client) ParseLink(link string) (*ObjectLocation, error) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse link: %w", err)
+	}
+
+	p := strings.TrimPrefix(u.Path, c.pathPrefix)
+	p = strings.TrimPrefix(p, "/")
+
+	endpointHost := c.client.EndpointURL().Host
+	if u.Host != endpointHost && strings.HasSuffix(u.Host, "."+endpointHost) {
+		bucket := strings.TrimSuffix(u.Host, "."+endpointHost)
+		if p == "" {
+			return nil, errors.New("link does not contain a key")
+		}
+
+		return &ObjectLocation{Bucket: bucket, Key: p}, nil
+	}
+
+	bucket, key, ok := strings.Cut(p, "/")
+	if !ok || bucket == "" || key == "" {
+		return nil, fmt.Errorf("link does not contain bucket and key: %s", u.Path)
+	}
+
+	return &ObjectLocation{Bucket: bucket, Key: key}, nil
+}
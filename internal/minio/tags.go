@@ -0,0 +1,186 @@
+package minio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/tags"
+
+	"github.com/devusSs/minls/internal/log"
+)
+
+// MaxTags is the maximum number of tags per object,
+// S3 does not allow more than 10 object tags.
+// Object tags not written by minls count towards the limit.
+const MaxTags = 10
+
+// ErrTooManyTags is returned if the minls tags and the other
+// tags of an object exceed MaxTags.
+var ErrTooManyTags = errors.New("too many object tags")
+
+// tagKeyPrefix marks object tags written by minls,
+// other object tags are left alone.
+const tagKeyPrefix = "minls/"
+
+// maxTagLength keeps the tag key within the S3 limit of 128 characters.
+const maxTagLength = 64
+
+// validTag are the characters S3 allows in tag keys minus spaces.
+var validTag = regexp.MustCompile(`^[a-zA-Z0-9+\-._:/@=]+$`)
+
+// ValidateTags checks whether the tags can be stored as object tags.
+func ValidateTags(t []string) error {
+	if len(t) > MaxTags {
+		return fmt.Errorf("%w: %d (max %d)", ErrTooManyTags, len(t), MaxTags)
+	}
+
+	for _, tag := range t {
+		if len(tag) > maxTagLength || !validTag.MatchString(tag) {
+			return fmt.Errorf(
+				"invalid tag %q: use at most %d letters, digits or +-._:/@=",
+				tag,
+				maxTagLength,
+			)
+		}
+	}
+
+	return nil
+}
+
+// SetObjectTags replaces the minls tags of the object with t.
+func (c *Client) SetObjectTags(ctx context.Context, bucket string, key string, t []string) error {
+	if ctx == nil {
+		return errors.New("context cannot be nil")
+	}
+
+	err := ValidateTags(t)
+	if err != nil {
+		return err
+	}
+
+	current, err := c.client.GetObjectTagging(ctx, bucket, key, minio.GetObjectTaggingOptions{})
+	if err != nil {
+		return fmt.Errorf("could not get object tags: %w", err)
+	}
+
+	m, err := objectTags(current.ToMap(), t)
+	if err != nil {
+		return err
+	}
+
+	otags, err := tags.MapToObjectTags(m)
+	if err != nil {
+		return fmt.Errorf("could not build object tags: %w", err)
+	}
+
+	err = c.client.PutObjectTagging(ctx, bucket, key, otags, minio.PutObjectTaggingOptions{})
+	if err != nil {
+		return fmt.Errorf("could not put object tags: %w", err)
+	}
+
+	log.Debug(
+		"minio - *client.SetObjectTags",
+		slog.String("bucket", bucket),
+		slog.String("key", key),
+		slog.Any("tags", otags.ToMap()),
+	)
+
+	return nil
+}
+
+// ObjectTags returns the minls tags of the object sorted by name.
+func (c *Client) ObjectTags(ctx context.Context, bucket string, key string) ([]string, error) {
+	if ctx == nil {
+		return nil, errors.New("context cannot be nil")
+	}
+
+	current, err := c.client.GetObjectTagging(ctx, bucket, key, minio.GetObjectTaggingOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("could not get object tags: %w", err)
+	}
+
+	t := make([]string, 0)
+	for k := range current.ToMap() {
+		if tag, ok := strings.CutPrefix(k, tagKeyPrefix); ok {
+			t = append(t, tag)
+		}
+	}
+
+	slices.Sort(t)
+
+	return t, nil
+}
+
+// objectTags replaces the minls tags in current with t,
+// it fails with ErrTooManyTags if the result exceeds MaxTags.
+func objectTags(current map[string]string, t []string) (map[string]string, error) {
+	m := make(map[string]string, len(current)+len(t))
+	for k, v := range current {
+		if !strings.HasPrefix(k, tagKeyPrefix) {
+			m[k] = v
+		}
+	}
+
+	other := len(m)
+
+	for _, tag := range t {
+		m[tagKeyPrefix+tag] = ""
+	}
+
+	if len(m) > MaxTags {
+		return nil, fmt.Errorf(
+			"%w: %d tags and %d tags not set by minls exceed the limit of %d, remove %d",
+			ErrTooManyTags,
+			len(m)-other,
+			other,
+			MaxTags,
+			len(m)-MaxTags,
+		)
+	}
+
+	return m, nil
+}
+
+// Object metadata written by UploadFile, values are url encoded
+// since metadata is sent as HTTP headers.
+const (
+	metaOriginalName = "Minls-Original-Name"
+	metaNote         = "Minls-Note"
+)
+
+func objectMetadata(originalName string, note string) map[string]string {
+	m := map[string]string{metaOriginalName: url.PathEscape(originalName)}
+	if note != "" {
+		m[metaNote] = url.PathEscape(note)
+	}
+
+	return m
+}
+
+// OriginalName returns the original file name stored on upload.
+func (oi *ObjectInfo) OriginalName() string {
+	return oi.metadata(metaOriginalName)
+}
+
+// Note returns the note stored on upload.
+func (oi *ObjectInfo) Note() string {
+	return oi.metadata(metaNote)
+}
+
+func (oi *ObjectInfo) metadata(key string) string {
+	v := oi.UserMetadata[key]
+
+	unescaped, err := url.PathUnescape(v)
+	if err != nil {
+		return v
+	}
+
+	return unescaped
+}
@@ -0,0 +1,67 @@
+package minio
+
+import (
+	"errors"
+	"maps"
+	"strconv"
+	"testing"
+)
+
+func TestObjectTags(t *testing.T) {
+	foreign := make(map[string]string)
+	for i := range 8 {
+		foreign["other"+strconv.Itoa(i)] = "v"
+	}
+
+	tests := []struct {
+		name    string
+		current map[string]string
+		tags    []string
+		want    map[string]string
+		wantErr error
+	}{
+		{
+			name: "new object",
+			tags: []string{"a", "b"},
+			want: map[string]string{"minls/a": "", "minls/b": ""},
+		},
+		{
+			name:    "replaces minls tags and keeps foreign tags",
+			current: map[string]string{"minls/old": "", "owner": "ops"},
+			tags:    []string{"new"},
+			want:    map[string]string{"minls/new": "", "owner": "ops"},
+		},
+		{
+			name:    "duplicate tags count once",
+			current: foreign,
+			tags:    []string{"a", "b", "a"},
+			want:    merge(foreign, map[string]string{"minls/a": "", "minls/b": ""}),
+		},
+		{
+			name:    "foreign tags count towards the limit",
+			current: foreign,
+			tags:    []string{"a", "b", "c"},
+			wantErr: ErrTooManyTags,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := objectTags(tt.current, tt.tags)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func merge(a map[string]string, b map[string]string) map[string]string {
+	m := maps.Clone(a)
+	maps.Copy(m, b)
+
+	return m
+}
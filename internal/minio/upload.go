@@ -15,19 +15,45 @@ import (
 	"github.com/devusSs/minls/internal/log"
 )
 
-func (c *Client) UploadFile(ctx context.Context, filePath string, public bool) (string, error) {
+// UploadInfo describes an uploaded file.
+type UploadInfo struct {
+	// Link is a public link or a presigned link for private uploads.
+	Link         string
+	Bucket       string
+	Key          string
+	OriginalName string
+	ContentType  string
+	Size         int64
+}
+
+// UploadOptions are optional settings for UploadFile.
+type UploadOptions struct {
+	// Tags are stored as object tags, see SetObjectTags.
+	Tags []string
+	// Note is stored in the object metadata.
+	Note string
+}
+
+// UploadFile uploads the file to the public or private bucket,
+// opts may be nil.
+func (c *Client) UploadFile(
+	ctx context.Context,
+	filePath string,
+	public bool,
+	opts *UploadOptions,
+) (*UploadInfo, error) {
 	if ctx == nil {
-		return "", errors.New("context cannot be nil")
+		return nil, errors.New("context cannot be nil")
 	}
 
 	err := c.createBucket(ctx, public)
 	if err != nil {
-		return "", fmt.Errorf("could not create bucket: %w", err)
+		return nil, fmt.Errorf("could not create bucket: %w", err)
 	}
 
 	ct, err := findContentType(filePath)
 	if err != nil {
-		return "", fmt.Errorf("could not find content type: %w", err)
+		return nil, fmt.Errorf("could not find content type: %w", err)
 	}
 
 	log.Debug(
@@ -38,7 +64,7 @@ func (c *Client) UploadFile(ctx context.Context, filePath string, public bool) (
 
 	fileName, err := randomizeFileName(filePath)
 	if err != nil {
-		return "", fmt.Errorf("could not randomize file name: %w", err)
+		return nil, fmt.Errorf("could not randomize file name: %w", err)
 	}
 
 	log.Debug(
@@ -47,10 +73,7 @@ func (c *Client) UploadFile(ctx context.Context, filePath string, public bool) (
 		slog.String("file_name", fileName),
 	)
 
-	bucketName := bucketNamePrivate
-	if public {
-		bucketName = bucketNamePublic
-	}
+	bucketName := c.Bucket(public)
 
 	log.Debug(
 		"minio - *client.UploadFile",
@@ -58,11 +81,27 @@ func (c *Client) UploadFile(ctx context.Context, filePath string, public bool) (
 		slog.String("bucket_name", bucketName),
 	)
 
+	if opts == nil {
+		opts = &UploadOptions{}
+	}
+
+	err = ValidateTags(opts.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	userTags, err := objectTags(nil, opts.Tags)
+	if err != nil {
+		return nil, err
+	}
+
 	info, err := c.client.FPutObject(ctx, bucketName, fileName, filePath, minio.PutObjectOptions{
-		ContentType: ct,
+		ContentType:  ct,
+		UserMetadata: objectMetadata(filepath.Base(filePath), opts.Note),
+		UserTags:     userTags,
 	})
 	if err != nil {
-		return "", fmt.Errorf("could not fput object: %w", err)
+		return nil, fmt.Errorf("could not fput object: %w", err)
 	}
 
 	log.Debug(
@@ -74,10 +113,29 @@ func (c *Client) UploadFile(ctx context.Context, filePath string, public bool) (
 		slog.Any("info", info),
 	)
 
+	ui := &UploadInfo{
+		Bucket:       bucketName,
+		Key:          info.Key,
+		OriginalName: filepath.Base(filePath),
+		ContentType:  ct,
+		Size:         info.Size,
+	}
+
+	ui.Link, err = c.ObjectLink(ctx, bucketName, info.Key, public)
+	if err != nil {
+		return nil, err
+	}
+
+	return ui, nil
+}
+
+// ObjectLink returns the public link of the object
+// or a new presigned link for private objects.
+func (c *Client) ObjectLink(ctx context.Context, bucket string, key string, public bool) (string, error) {
 	if public {
-		link := fmt.Sprintf("%s/%s/%s", c.client.EndpointURL().String(), bucketName, info.Key)
+		link := c.objectURL(bucket, key).String()
 		log.Debug(
-			"minio - *client.UploadFile",
+			"minio - *client.ObjectLink",
 			slog.String("action", "return"),
 			slog.String("warn", "link is public, returning early"),
 			slog.String("link", link),
@@ -85,17 +143,19 @@ func (c *Client) UploadFile(ctx context.Context, filePath string, public bool) (
 		return link, nil
 	}
 
-	link, err := c.client.PresignedGetObject(ctx, bucketName, fileName, objectExpiry, nil)
+	link, err := c.client.PresignedGetObject(ctx, bucket, key, c.linkExpiry, nil)
 	if err != nil {
 		return "", fmt.Errorf("could not get presigned url: %w", err)
 	}
 
+	link = c.withPathPrefix(link)
+
 	log.Debug(
-		"minio - *client.UploadFile",
+		"minio - *client.ObjectLink",
 		slog.String("action", "presigned_get_object"),
-		slog.String("bucket_name", bucketName),
-		slog.String("file_name", fileName),
-		slog.Duration("object_expiry", objectExpiry),
+		slog.String("bucket_name", bucket),
+		slog.String("key", key),
+		slog.Duration("link_expiry", c.linkExpiry),
 		slog.String("link", link.String()),
 	)
 
@@ -107,10 +167,7 @@ func (c *Client) createBucket(ctx context.Context, public bool) error {
 		return errors.New("context cannot be nil")
 	}
 
-	bucket := bucketNamePrivate
-	if public {
-		bucket = bucketNamePublic
-	}
+	bucket := c.Bucket(public)
 
 	log.Debug(
 		"minio - *client.createBucket",
@@ -180,8 +237,8 @@ func (c *Client) createBucket(ctx context.Context, public bool) error {
 }
 
 const (
-	bucketNamePublic           = "minls-public"
-	bucketNamePrivate          = "minls-private"
+	DefaultBucketPublic        = "minls-public"
+	DefaultBucketPrivate       = "minls-private"
 	bucketRegion               = "us-east-1"
 	bucketObjectLocking        = false
 	bucketPolicyPublicTemplate = `{
@@ -204,7 +261,8 @@ const (
 			}
 		]
 	}`
-	objectExpiry = 7 * 24 * time.Hour
+	DefaultLinkExpiry = 7 * 24 * time.Hour
+	MaxLinkExpiry     = 7 * 24 * time.Hour
 )
 
 func findContentType(filePath string) (string, error) {
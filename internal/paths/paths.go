@@ -0,0 +1,345 @@
+// Package paths resolves where minls stores its data, logs and config.
+//
+// Locations follow the XDG base directory specification:
+// data in $XDG_DATA_HOME/minls, logs in $XDG_STATE_HOME/minls/logs
+// and config in $XDG_CONFIG_HOME/minls. On Windows data and logs default to
+// %LocalAppData%\minls\data and \logs, the config to %AppData%\minls.
+// Each can be overridden with the environment variables below
+// (which the global flags set).
+package paths
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const appName = "minls"
+
+// Environment variables overriding the resolved directories.
+const (
+	EnvDataDir   = "MINLS_DATA_DIR"
+	EnvLogDir    = "MINLS_LOG_DIR"
+	EnvConfigDir = "MINLS_CONFIG_DIR"
+)
+
+// Data returns the directory of the upload history.
+func Data() (string, error) {
+	return resolve(&location{
+		override:    EnvDataDir,
+		xdgVar:      "XDG_DATA_HOME",
+		homeDefault: filepath.Join(".local", "share"),
+		windowsBase: os.UserCacheDir,
+		windowsSub:  "data",
+	})
+}
+
+// Logs returns the directory of the log files.
+func Logs() (string, error) {
+	return resolve(&location{
+		override:    EnvLogDir,
+		xdgVar:      "XDG_STATE_HOME",
+		homeDefault: filepath.Join(".local", "state"),
+		sub:         "logs",
+		windowsBase: os.UserCacheDir,
+		windowsSub:  "logs",
+	})
+}
+
+// Config returns the directory of the configuration (e.g. the .env file).
+func Config() (string, error) {
+	return resolve(&location{
+		override:    EnvConfigDir,
+		xdgVar:      "XDG_CONFIG_HOME",
+		homeDefault: ".config",
+		windowsBase: os.UserConfigDir,
+	})
+}
+
+// location describes where a directory is found.
+type location struct {
+	override string
+	xdgVar   string
+	// homeDefault is the XDG base relative to the home dir if xdgVar is unset.
+	homeDefault string
+	sub         string
+	// windowsBase replaces the home default on Windows, data and logs go to
+	// %LocalAppData% and the config to %AppData%, each in its own dir
+	// so clearing one never touches the others.
+	windowsBase func() (string, error)
+	windowsSub  string
+}
+
+// resolve returns the override if set, else <xdg base>/minls/<sub>.
+// Relative XDG variables are invalid per spec and ignored.
+func resolve(l *location) (string, error) {
+	if dir := os.Getenv(l.override); dir != "" {
+		return filepath.Abs(dir)
+	}
+
+	base := os.Getenv(l.xdgVar)
+	if base != "" && filepath.IsAbs(base) {
+		return filepath.Join(base, appName, l.sub), nil
+	}
+
+	if runtime.GOOS == "windows" {
+		dir, err := l.windowsBase()
+		if err != nil {
+			return "", fmt.Errorf("could not find windows app data dir: %w", err)
+		}
+
+		return filepath.Join(dir, appName, l.windowsSub), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("could not find home dir: %w", err)
+	}
+
+	return filepath.Join(home, l.homeDefault, appName, l.sub), nil
+}
+
+// legacyDir is the directory files were stored in
+// before minls used the XDG locations, the directory of the executable.
+var legacyDir = func() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("could not find executable: %w", err)
+	}
+
+	return filepath.Dir(exe), nil
+}
+
+// legacyMarker is created in the data dir once MigrateLegacy ran.
+const legacyMarker = ".legacy_migrated"
+
+// RemoveLegacyMarker removes the marker of MigrateLegacy from the data dir.
+func RemoveLegacyMarker() error {
+	data, err := Data()
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(filepath.Join(data, legacyMarker))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("could not remove %s: %w", legacyMarker, err)
+	}
+
+	return nil
+}
+
+// MigrateLegacy moves data, logs and the .env file from the legacy location
+// next to the executable to the current locations. Only directories
+// containing minls files and a .env configuring minio are moved, targets
+// which already contain files are left alone. It runs once, later calls
+// return without looking at the legacy location.
+// It returns a description of every move.
+func MigrateLegacy() ([]string, error) {
+	data, err := Data()
+	if err != nil {
+		return nil, err
+	}
+
+	marker := filepath.Join(data, legacyMarker)
+
+	_, err = os.Stat(marker)
+	if err == nil {
+		return nil, nil
+	}
+
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("could not check %s: %w", marker, err)
+	}
+
+	old, err := legacyDir()
+	if err != nil {
+		return nil, err
+	}
+
+	logs, err := Logs()
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := Config()
+	if err != nil {
+		return nil, err
+	}
+
+	moves := []struct {
+		from, to string
+		isMinls  func(path string) bool
+	}{
+		{filepath.Join(old, "data"), data, containsMatch("minls.db", "minls.data.json")},
+		{filepath.Join(old, "logs"), logs, containsMatch("minls_*.log.json")},
+		{filepath.Join(old, ".env"), filepath.Join(config, ".env"), isMinlsDotEnv},
+	}
+
+	moved := make([]string, 0)
+
+	for _, m := range moves {
+		if !m.isMinls(m.from) {
+			continue
+		}
+
+		ok, err := move(m.from, m.to)
+		if err != nil {
+			return moved, fmt.Errorf("could not move %s to %s: %w", m.from, m.to, err)
+		}
+
+		if ok {
+			moved = append(moved, fmt.Sprintf("moved %s to %s", m.from, m.to))
+		}
+	}
+
+	err = os.MkdirAll(data, 0700)
+	if err != nil {
+		return moved, fmt.Errorf("could not create data dir: %w", err)
+	}
+
+	err = os.WriteFile(marker, nil, 0600)
+	if err != nil {
+		return moved, fmt.Errorf("could not write %s: %w", marker, err)
+	}
+
+	return moved, nil
+}
+
+// containsMatch returns a check whether a directory contains a file matching one of the patterns.
+func containsMatch(patterns ...string) func(dir string) bool {
+	return func(dir string) bool {
+		for _, p := range patterns {
+			matches, err := filepath.Glob(filepath.Join(dir, p))
+			if err == nil && len(matches) > 0 {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// isMinlsDotEnv reports whether the file is a .env configuring minls.
+func isMinlsDotEnv(path string) bool {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+
+	for line := range strings.Lines(string(content)) {
+		line = strings.TrimPrefix(strings.TrimSpace(line), "export ")
+		if strings.HasPrefix(line, "MINIO_ENDPOINT=") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// move renames from to to if from exists and to is missing or an empty directory.
+func move(from string, to string) (bool, error) {
+	if from == to {
+		return false, nil
+	}
+
+	_, err := os.Stat(from)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	entries, err := os.ReadDir(to)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil && !isNotDir(to):
+		return false, err
+	case err != nil || len(entries) > 0:
+		// the target is a file or a directory with files
+		return false, nil
+	default:
+		err = os.Remove(to)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	err = os.MkdirAll(filepath.Dir(to), 0700)
+	if err != nil {
+		return false, err
+	}
+
+	err = os.Rename(from, to)
+	if err == nil {
+		return true, nil
+	}
+
+	// renaming fails across file systems, copy and remove instead
+	err = copyTree(from, to)
+	if err != nil {
+		os.RemoveAll(to)
+		return false, err
+	}
+
+	return true, os.RemoveAll(from)
+}
+
+// copyTree copies the file or directory from to to, keeping permissions.
+func copyTree(from string, to string) error {
+	return filepath.WalkDir(from, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(from, path)
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(to, rel)
+
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			return os.MkdirAll(target, fi.Mode().Perm())
+		}
+
+		return copyFile(path, target, fi.Mode().Perm())
+	})
+}
+
+func copyFile(from string, to string, perm os.FileMode) error {
+	src, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(to, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(dst, src)
+	if err != nil {
+		dst.Close()
+		return err
+	}
+
+	return dst.Close()
+}
+
+func isNotDir(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && !fi.IsDir()
+}
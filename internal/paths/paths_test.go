@@ -0,0 +1,137 @@
+package paths
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMigrateLegacy(t *testing.T) {
+	old := t.TempDir()
+	base := t.TempDir()
+
+	t.Setenv(EnvDataDir, filepath.Join(base, "data"))
+	t.Setenv(EnvLogDir, filepath.Join(base, "logs"))
+	t.Setenv(EnvConfigDir, filepath.Join(base, "config"))
+
+	orig := legacyDir
+	legacyDir = func() (string, error) { return old, nil }
+	t.Cleanup(func() { legacyDir = orig })
+
+	files := map[string]string{
+		"data/minls.db":      "db",
+		"logs/other.log":     "unrelated",
+		".env":               "MINIO_ENDPOINT=localhost:9000\n",
+		"unrelated/minls.db": "db",
+	}
+
+	for name, content := range files {
+		path := filepath.Join(old, name)
+
+		err := os.MkdirAll(filepath.Dir(path), 0700)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = os.WriteFile(path, []byte(content), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	moved, err := MigrateLegacy()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(moved) != 2 {
+		t.Errorf("moved %v, want data and .env", moved)
+	}
+
+	exists := map[string]bool{
+		filepath.Join(base, "data", "minls.db"):  true,
+		filepath.Join(base, "config", ".env"):    true,
+		filepath.Join(old, "logs", "other.log"):  true,
+		filepath.Join(base, "logs", "other.log"): false,
+	}
+
+	for path, want := range exists {
+		_, err := os.Stat(path)
+		if got := err == nil; got != want {
+			t.Errorf("%s exists = %t, want %t", path, got, want)
+		}
+	}
+
+	// later runs do not look at the legacy location again
+	err = os.WriteFile(filepath.Join(old, "logs", "minls_1.log.json"), nil, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	moved, err = MigrateLegacy()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(moved) != 0 {
+		t.Errorf("second run moved %v", moved)
+	}
+}
+
+func TestMigrateLegacyUnrelatedDotEnv(t *testing.T) {
+	old := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(old, ".env"), []byte("DATABASE_URL=postgres://\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if isMinlsDotEnv(filepath.Join(old, ".env")) {
+		t.Error("unrelated .env detected as minls config")
+	}
+
+	err = os.WriteFile(filepath.Join(old, ".env"), []byte("export MINIO_ENDPOINT=minio:9000\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !isMinlsDotEnv(filepath.Join(old, ".env")) {
+		t.Error("minls .env not detected")
+	}
+}
+
+func TestResolveSeparatesDirs(t *testing.T) {
+	home := t.TempDir()
+
+	t.Setenv("HOME", home)
+
+	for _, v := range []string{EnvDataDir, EnvLogDir, EnvConfigDir, "XDG_DATA_HOME", "XDG_STATE_HOME", "XDG_CONFIG_HOME"} {
+		t.Setenv(v, "")
+	}
+
+	dirs := make(map[string]string)
+
+	for name, fn := range map[string]func() (string, error){"data": Data, "logs": Logs, "config": Config} {
+		dir, err := fn()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if other, ok := dirs[dir]; ok {
+			t.Errorf("%s and %s share %s", name, other, dir)
+		}
+
+		dirs[dir] = name
+	}
+
+	t.Setenv("XDG_DATA_HOME", "relative")
+
+	dir, err := Data()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := filepath.Join(home, ".local", "share", "minls"); dir != want {
+		t.Errorf("relative XDG_DATA_HOME used: %s, want %s", dir, want)
+	}
+}
@@ -0,0 +1,303 @@
+// Package search implements the fuzzy matching and ranking
+// used to search the upload history.
+package search
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Field is a searchable text with a weight,
+// matches in fields with a higher weight rank higher.
+type Field struct {
+	Name   string
+	Text   string
+	Weight int
+	// Long fields (e.g. file content) are only matched by words,
+	// subsequence matching would match almost anything in them.
+	Long bool
+}
+
+// Document is a searchable item identified by ID.
+type Document struct {
+	ID     int
+	Fields []Field
+}
+
+// Result is a matching document with its score.
+type Result struct {
+	ID    int
+	Score int
+	// Fields are the names of the fields that matched, best match first.
+	Fields []string
+	// Snippet is the surrounding text of the best match in a long field.
+	Snippet string
+}
+
+// Search returns all documents matching every term of the query,
+// best matches first. Empty queries match nothing.
+func Search(query string, docs []Document) []Result {
+	terms := strings.Fields(strings.ToLower(query))
+	if len(terms) == 0 {
+		return nil
+	}
+
+	results := make([]Result, 0)
+
+	for _, doc := range docs {
+		r, ok := matchDocument(terms, doc)
+		if ok {
+			results = append(results, r)
+		}
+	}
+
+	slices.SortStableFunc(results, func(a Result, b Result) int {
+		return cmp.Or(cmp.Compare(b.Score, a.Score), cmp.Compare(b.ID, a.ID))
+	})
+
+	return results
+}
+
+func matchDocument(terms []string, doc Document) (Result, bool) {
+	r := Result{ID: doc.ID}
+	scores := make(map[string]int)
+	longField := ""
+
+	for _, term := range terms {
+		best := 0
+		bestField := -1
+
+		for i, f := range doc.Fields {
+			s := matchTerm(term, strings.ToLower(f.Text), f.Long) * f.Weight
+			if s > best {
+				best = s
+				bestField = i
+			}
+		}
+
+		if bestField == -1 {
+			return Result{}, false
+		}
+
+		r.Score += best
+
+		f := doc.Fields[bestField]
+		scores[f.Name] = max(scores[f.Name], best)
+
+		if f.Long {
+			longField = f.Text
+		}
+	}
+
+	if longField != "" {
+		r.Snippet = bestSnippet(longField, terms)
+	}
+
+	for name := range scores {
+		r.Fields = append(r.Fields, name)
+	}
+
+	slices.SortFunc(r.Fields, func(a string, b string) int {
+		return cmp.Or(cmp.Compare(scores[b], scores[a]), strings.Compare(a, b))
+	})
+
+	return r, true
+}
+
+// Scores of the match kinds, a field weight is applied on top.
+const (
+	scoreExact       = 100
+	scoreWordPrefix  = 60
+	scoreSubstring   = 40
+	scoreTypo        = 25
+	scoreSubsequence = 10
+)
+
+// matchTerm scores how well the lower case term matches the lower case text,
+// 0 means no match.
+func matchTerm(term string, text string, long bool) int {
+	if text == "" {
+		return 0
+	}
+
+	if text == term {
+		return scoreExact
+	}
+
+	words := splitWords(text)
+
+	best := 0
+
+	if strings.Contains(text, term) {
+		best = scoreSubstring
+
+		for _, w := range words {
+			if w == term {
+				return scoreExact - 10
+			}
+
+			if strings.HasPrefix(w, term) {
+				best = scoreWordPrefix
+			}
+		}
+
+		return best
+	}
+
+	maxDist := maxTypos(term)
+	if maxDist > 0 {
+		termLen := utf8.RuneCountInString(term)
+
+		for _, w := range words {
+			// compare runes, not bytes, so umlauts do not count as typos
+			if abs(utf8.RuneCountInString(w)-termLen) <= maxDist && editDistance(term, w) <= maxDist {
+				return scoreTypo
+			}
+		}
+	}
+
+	if !long && isSubsequence(term, text) {
+		return scoreSubsequence
+	}
+
+	return 0
+}
+
+// maxTypos is the edit distance tolerated for a term,
+// short terms have to match exactly.
+func maxTypos(term string) int {
+	n := len([]rune(term))
+
+	switch {
+	case n >= 8:
+		return 2
+	case n >= 4:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func splitWords(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func isSubsequence(term string, text string) bool {
+	rest := []rune(term)
+
+	for _, r := range text {
+		if len(rest) == 0 {
+			break
+		}
+
+		if r == rest[0] {
+			rest = rest[1:]
+		}
+	}
+
+	return len(rest) == 0
+}
+
+// editDistance is the optimal string alignment distance,
+// swapped neighbouring characters count as a single typo.
+func editDistance(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+
+	return d[len(ra)][len(rb)]
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+
+	return n
+}
+
+// bestSnippet prefers the whole query phrase over single terms
+// and longer terms over shorter ones.
+func bestSnippet(text string, terms []string) string {
+	candidates := append([]string{strings.Join(terms, " ")}, terms...)
+	slices.SortStableFunc(candidates[1:], func(a string, b string) int {
+		return cmp.Compare(len(b), len(a))
+	})
+
+	for _, c := range candidates {
+		s := snippet(text, c)
+		if s != "" {
+			return s
+		}
+	}
+
+	return ""
+}
+
+// snippetRadius is how many characters are shown around a match.
+const snippetRadius = 40
+
+// snippet returns the line around the first occurrence of term in text.
+func snippet(text string, term string) string {
+	lower := strings.ToLower(text)
+
+	i := strings.Index(lower, term)
+	if i == -1 || len(lower) != len(text) {
+		// the term only matched fuzzily or lowering changed the byte offsets
+		return ""
+	}
+
+	start := max(i-snippetRadius, 0)
+	prefix := start > 0
+	if j := strings.LastIndexByte(text[start:i], '\n'); j != -1 {
+		start += j + 1
+		prefix = false
+	}
+
+	end := min(i+len(term)+snippetRadius, len(text))
+	suffix := end < len(text)
+	if j := strings.IndexByte(text[i:end], '\n'); j != -1 {
+		end = i + j
+		suffix = false
+	}
+
+	s := strings.TrimSpace(strings.ToValidUTF8(text[start:end], ""))
+
+	if prefix {
+		s = "..." + s
+	}
+
+	if suffix {
+		s += "..."
+	}
+
+	return s
+}
@@ -0,0 +1,178 @@
+package search
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestMatchTerm(t *testing.T) {
+	tests := []struct {
+		name string
+		term string
+		text string
+		long bool
+		want int
+	}{
+		{name: "empty text", term: "a", text: "", want: 0},
+		{name: "exact", term: "report.pdf", text: "report.pdf", want: scoreExact},
+		{name: "whole word", term: "report", text: "annual report.pdf", want: scoreExact - 10},
+		{name: "word prefix", term: "rep", text: "annual report.pdf", want: scoreWordPrefix},
+		{name: "substring", term: "port", text: "annual report.pdf", want: scoreSubstring},
+		{name: "one typo", term: "reprot", text: "annual report.pdf", want: scoreTypo},
+		{name: "two typos in long terms", term: "screnshto", text: "old screenshot", want: scoreTypo},
+		{name: "three typos", term: "scrnshto", text: "old screenshot", want: 0},
+		{name: "short term needs exact", term: "cta", text: "cat", want: 0},
+		{name: "subsequence", term: "arp", text: "annual report", want: scoreSubsequence},
+		{name: "no subsequence in long fields", term: "arp", text: "annual report", long: true, want: 0},
+		{name: "no match", term: "zebra", text: "annual report", want: 0},
+		{name: "unicode typo", term: "straße", text: "die strasse", want: 0},
+		{name: "unicode word", term: "grüße", text: "viele grüße", want: scoreExact - 10},
+		{name: "unicode transposition", term: "grüeß", text: "viele grüße", want: scoreTypo},
+		{name: "unicode lengths in runes", term: "ääbc", text: "äbc", want: scoreTypo},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchTerm(tt.term, tt.text, tt.long); got != tt.want {
+				t.Errorf("matchTerm(%q, %q, %t) = %d, want %d", tt.term, tt.text, tt.long, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a    string
+		b    string
+		want int
+	}{
+		{a: "", b: "", want: 0},
+		{a: "", b: "abc", want: 3},
+		{a: "abc", b: "abc", want: 0},
+		{a: "abc", b: "abd", want: 1},
+		{a: "abc", b: "ab", want: 1},
+		{a: "abc", b: "acb", want: 1},
+		{a: "kitten", b: "sitting", want: 3},
+		{a: "grüße", b: "grusse", want: 3},
+		{a: "äö", b: "öä", want: 1},
+	}
+
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+
+		if got := editDistance(tt.b, tt.a); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	long := strings.Repeat("a", 60) + " needle " + strings.Repeat("b", 60)
+
+	tests := []struct {
+		name string
+		text string
+		term string
+		want string
+	}{
+		{name: "no match", text: "hello world", term: "zebra", want: ""},
+		{name: "short text", text: "hello world", term: "world", want: "hello world"},
+		{name: "case folded", text: "Hello World", term: "world", want: "Hello World"},
+		{
+			name: "both sides cut",
+			text: long,
+			term: "needle",
+			want: "..." + strings.Repeat("a", 39) + " needle " + strings.Repeat("b", 39) + "...",
+		},
+		{name: "stops at lines", text: "first line\nthe needle line\nlast line", term: "needle", want: "the needle line"},
+		{
+			name: "match at start",
+			text: "needle " + strings.Repeat("b", 60),
+			term: "needle",
+			want: "needle " + strings.Repeat("b", 39) + "...",
+		},
+		{
+			name: "match at end",
+			text: strings.Repeat("a", 60) + " needle",
+			term: "needle",
+			want: "..." + strings.Repeat("a", 39) + " needle",
+		},
+		{name: "offsets changed by lowering", text: "İstanbul needle", term: "needle", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := snippet(tt.text, tt.term); got != tt.want {
+				t.Errorf("snippet() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSnippetRuneBoundaries(t *testing.T) {
+	// every cut position lands inside a multi byte rune for some offset
+	for pad := range 4 {
+		text := strings.Repeat("x", pad) + strings.Repeat("ä", 30) + " needle " + strings.Repeat("ö", 30)
+
+		got := snippet(text, "needle")
+		if !utf8.ValidString(got) {
+			t.Errorf("pad %d: snippet is not valid utf-8: %q", pad, got)
+		}
+
+		if !strings.Contains(got, "needle") {
+			t.Errorf("pad %d: snippet lost the match: %q", pad, got)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	docs := []Document{
+		{ID: 1, Fields: []Field{{Name: "name", Text: "Annual Report.pdf", Weight: 3}}},
+		{ID: 2, Fields: []Field{
+			{Name: "name", Text: "notes.txt", Weight: 3},
+			{Name: "content", Text: "the annual budget", Weight: 1, Long: true},
+		}},
+		{ID: 3, Fields: []Field{
+			{Name: "name", Text: "report-draft.pdf", Weight: 3},
+			{Name: "tags", Text: "annual", Weight: 2},
+		}},
+	}
+
+	tests := []struct {
+		name    string
+		query   string
+		want    []int
+		snippet string
+	}{
+		{name: "empty query", query: "", want: nil},
+		{name: "blank query", query: "   ", want: nil},
+		{name: "case folding", query: "ANNUAL", want: []int{1, 3, 2}},
+		{name: "all terms must match", query: "annual report", want: []int{1, 3}},
+		{name: "typo", query: "reprot", want: []int{3, 1}},
+		{name: "snippet from long field", query: "budget", want: []int{2}, snippet: "the annual budget"},
+		{name: "no match", query: "annual zebra", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := Search(tt.query, docs)
+
+			var ids []int
+			for _, r := range results {
+				ids = append(ids, r.ID)
+			}
+
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("Search(%q) = %v, want %v", tt.query, ids, tt.want)
+			}
+
+			if tt.snippet != "" && (len(results) == 0 || results[0].Snippet != tt.snippet) {
+				t.Errorf("Search(%q) snippet = %+v, want %q", tt.query, results, tt.snippet)
+			}
+		})
+	}
+}
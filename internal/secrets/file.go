@@ -0,0 +1,260 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/devusSs/minls/internal/atomicfile"
+	"github.com/devusSs/minls/internal/paths"
+)
+
+// FileName is the name of the encrypted secrets file in the config dir.
+const FileName = "secrets.enc"
+
+// EnvPassphrase holds the passphrase of the secrets file,
+// else it is prompted for if a terminal is attached.
+const EnvPassphrase = "MINLS_SECRETS_PASSPHRASE"
+
+// scrypt parameters recommended for interactive logins.
+const (
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	keyLength    = 32
+	saltLength   = 16
+	fileVersion  = 1
+	filePermMode = 0600
+)
+
+// encryptedFile is the on disk format, data is the AES-GCM sealed
+// JSON object of all secrets keyed by account.
+type encryptedFile struct {
+	Version int    `json:"version"`
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+type fileStore struct {
+	path string
+	// passphrase and secrets are cached after the first access.
+	passphrase string
+	secrets    map[string]string
+}
+
+func newFileStore() (*fileStore, error) {
+	dir, err := paths.Config()
+	if err != nil {
+		return nil, fmt.Errorf("could not find config dir: %w", err)
+	}
+
+	return &fileStore{path: filepath.Join(dir, FileName)}, nil
+}
+
+func (s *fileStore) Name() string     { return BackendFile }
+func (s *fileStore) Location() string { return s.path }
+
+func (s *fileStore) Get(profile string, key string) (string, error) {
+	err := s.load()
+	if err != nil {
+		return "", err
+	}
+
+	v, ok := s.secrets[account(profile, key)]
+	if !ok {
+		return "", ErrNotFound
+	}
+
+	return v, nil
+}
+
+func (s *fileStore) Set(profile string, key string, value string) error {
+	err := s.load()
+	if err != nil {
+		return err
+	}
+
+	s.secrets[account(profile, key)] = value
+
+	return s.save()
+}
+
+func (s *fileStore) Delete(profile string, key string) error {
+	err := s.load()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := s.secrets[account(profile, key)]; !ok {
+		return ErrNotFound
+	}
+
+	delete(s.secrets, account(profile, key))
+
+	return s.save()
+}
+
+// load decrypts the file, a missing file is an empty store
+// and does not ask for the passphrase.
+func (s *fileStore) load() error {
+	if s.secrets != nil {
+		return nil
+	}
+
+	raw, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		s.secrets = make(map[string]string)
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("could not read secrets file: %w", err)
+	}
+
+	f := &encryptedFile{}
+
+	err = json.Unmarshal(raw, f)
+	if err != nil {
+		return fmt.Errorf("could not parse secrets file %s: %w", s.path, err)
+	}
+
+	if f.Version != fileVersion {
+		return fmt.Errorf("unsupported secrets file version %d", f.Version)
+	}
+
+	passphrase, err := readPassphrase(false)
+	if err != nil {
+		return err
+	}
+
+	aead, err := newAEAD(passphrase, f.Salt)
+	if err != nil {
+		return err
+	}
+
+	plain, err := aead.Open(nil, f.Nonce, f.Data, nil)
+	if err != nil {
+		return fmt.Errorf("could not decrypt %s (wrong passphrase?)", s.path)
+	}
+
+	secrets := make(map[string]string)
+
+	err = json.Unmarshal(plain, &secrets)
+	if err != nil {
+		return fmt.Errorf("could not parse decrypted secrets: %w", err)
+	}
+
+	s.passphrase = passphrase
+	s.secrets = secrets
+
+	return nil
+}
+
+// save encrypts the secrets with a new salt and nonce,
+// the passphrase is asked for (twice) when the file is created.
+func (s *fileStore) save() error {
+	if s.passphrase == "" {
+		passphrase, err := readPassphrase(true)
+		if err != nil {
+			return err
+		}
+
+		s.passphrase = passphrase
+	}
+
+	plain, err := json.Marshal(s.secrets)
+	if err != nil {
+		return fmt.Errorf("could not marshal secrets: %w", err)
+	}
+
+	f := &encryptedFile{Version: fileVersion, Salt: make([]byte, saltLength)}
+
+	_, err = rand.Read(f.Salt)
+	if err != nil {
+		return fmt.Errorf("could not generate salt: %w", err)
+	}
+
+	aead, err := newAEAD(s.passphrase, f.Salt)
+	if err != nil {
+		return err
+	}
+
+	f.Nonce = make([]byte, aead.NonceSize())
+
+	_, err = rand.Read(f.Nonce)
+	if err != nil {
+		return fmt.Errorf("could not generate nonce: %w", err)
+	}
+
+	f.Data = aead.Seal(nil, f.Nonce, plain, nil)
+
+	err = os.MkdirAll(filepath.Dir(s.path), 0700)
+	if err != nil {
+		return fmt.Errorf("could not create config dir: %w", err)
+	}
+
+	return atomicfile.Write(s.path, filePermMode, func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(f)
+	})
+}
+
+func newAEAD(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, keyLength)
+	if err != nil {
+		return nil, fmt.Errorf("could not derive key: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("could not create cipher: %w", err)
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// readPassphrase returns EnvPassphrase or prompts for the passphrase,
+// confirm asks twice to catch typos when a new file is created.
+func readPassphrase(confirm bool) (string, error) {
+	if p := os.Getenv(EnvPassphrase); p != "" {
+		return p, nil
+	}
+
+	if !IsTerminal() {
+		return "", fmt.Errorf("no terminal to ask for the secrets passphrase, set %s", EnvPassphrase)
+	}
+
+	p, err := ReadHidden("Secrets passphrase: ")
+	if err != nil {
+		return "", err
+	}
+
+	if p == "" {
+		return "", errors.New("empty passphrase")
+	}
+
+	if !confirm {
+		return p, nil
+	}
+
+	again, err := ReadHidden("Repeat passphrase: ")
+	if err != nil {
+		return "", err
+	}
+
+	if p != again {
+		return "", errors.New("passphrases do not match")
+	}
+
+	return p, nil
+}
@@ -0,0 +1,47 @@
+package secrets
+
+import (
+	"errors"
+
+	"github.com/zalando/go-keyring"
+)
+
+// service is the keyring service the secrets are stored under.
+const service = "minls"
+
+// probeAccount is looked up to check whether a keyring is reachable.
+const probeAccount = "probe"
+
+// keyringAvailable reports whether the OS keyring can be used,
+// a missing entry means the keyring answered.
+func keyringAvailable() bool {
+	_, err := keyring.Get(service, probeAccount)
+	return err == nil || errors.Is(err, keyring.ErrNotFound)
+}
+
+type keyringStore struct{}
+
+func (*keyringStore) Name() string     { return BackendKeyring }
+func (*keyringStore) Location() string { return "keyring service " + service }
+
+func (*keyringStore) Get(profile string, key string) (string, error) {
+	v, err := keyring.Get(service, account(profile, key))
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", ErrNotFound
+	}
+
+	return v, err
+}
+
+func (*keyringStore) Set(profile string, key string, value string) error {
+	return keyring.Set(service, account(profile, key), value)
+}
+
+func (*keyringStore) Delete(profile string, key string) error {
+	err := keyring.Delete(service, account(profile, key))
+	if errors.Is(err, keyring.ErrNotFound) {
+		return ErrNotFound
+	}
+
+	return err
+}
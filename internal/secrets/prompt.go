@@ -0,0 +1,27 @@
+package secrets
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// IsTerminal reports whether stdin is a terminal.
+func IsTerminal() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// ReadHidden prompts on stderr and reads a line from the terminal without echo.
+func ReadHidden(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+
+	b, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+
+	if err != nil {
+		return "", fmt.Errorf("could not read input: %w", err)
+	}
+
+	return string(b), nil
+}
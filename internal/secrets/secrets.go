@@ -0,0 +1,96 @@
+// Package secrets stores credentials outside of plaintext config files.
+//
+// Secrets live in the OS keyring (the Secret Service on Linux desktops,
+// the Keychain on macOS, the Credential Manager on Windows). Headless
+// machines without a keyring fall back to a file in the config dir which
+// is encrypted with a passphrase (see EnvPassphrase).
+//
+// Secrets are stored per profile under the environment variable
+// of their config key, e.g. default/MINIO_ACCESS_SECRET.
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Backends of a Store, BackendAuto uses the keyring if available, else the file.
+const (
+	BackendAuto    = "auto"
+	BackendKeyring = "keyring"
+	BackendFile    = "file"
+	BackendNone    = "none"
+)
+
+// EnvBackend selects the backend, see Backends.
+const EnvBackend = "MINLS_SECRETS_BACKEND"
+
+// ErrNotFound is returned if a secret is not stored.
+var ErrNotFound = errors.New("secret not found")
+
+// Store reads and writes secrets.
+type Store interface {
+	// Name is the backend name.
+	Name() string
+	// Location describes where the secrets are stored, e.g. the file path.
+	Location() string
+	Get(profile string, key string) (string, error)
+	Set(profile string, key string, value string) error
+	Delete(profile string, key string) error
+}
+
+// Open opens the store of the backend, empty uses EnvBackend or BackendAuto.
+func Open(backend string) (Store, error) {
+	if backend == "" {
+		backend = os.Getenv(EnvBackend)
+	}
+
+	switch strings.ToLower(backend) {
+	case "", BackendAuto:
+		if keyringAvailable() {
+			return &keyringStore{}, nil
+		}
+
+		return newFileStore()
+	case BackendKeyring:
+		if !keyringAvailable() {
+			return nil, errors.New("no keyring available (is a secret service running?)")
+		}
+
+		return &keyringStore{}, nil
+	case BackendFile:
+		return newFileStore()
+	case BackendNone:
+		return noneStore{}, nil
+	default:
+		return nil, fmt.Errorf(
+			"unknown secrets backend %q (options: %s / %s / %s / %s)",
+			backend,
+			BackendAuto,
+			BackendKeyring,
+			BackendFile,
+			BackendNone,
+		)
+	}
+}
+
+// account is the name a secret is stored under.
+func account(profile string, key string) string {
+	return profile + "/" + key
+}
+
+// noneStore disables secret storage, e.g. for CI.
+type noneStore struct{}
+
+func (noneStore) Name() string     { return BackendNone }
+func (noneStore) Location() string { return "" }
+
+func (noneStore) Get(string, string) (string, error) { return "", ErrNotFound }
+
+func (noneStore) Set(string, string, string) error {
+	return errors.New("secret storage is disabled (" + EnvBackend + "=" + BackendNone + ")")
+}
+
+func (noneStore) Delete(string, string) error { return ErrNotFound }
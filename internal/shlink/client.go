@@ -0,0 +1,132 @@
+package shlink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/devusSs/minls/internal/log"
+	"github.com/devusSs/minls/internal/shortener"
+)
+
+// Client talks to the REST API (v3) of a Shlink instance.
+type Client struct {
+	endpoint string
+	apiKey   string
+	client   *http.Client
+}
+
+func NewClient(endpoint string, apiKey string) *Client {
+	if !strings.Contains(endpoint, "https://") && !strings.Contains(endpoint, "http://") {
+		log.Warn(
+			"shlink - NewClient",
+			slog.String("warn", "endpoint does not contain schema, adding 'http://'"),
+		)
+		endpoint = "http://" + endpoint
+	}
+
+	if !strings.Contains(endpoint, "https://") {
+		log.Warn("shlink - NewClient", slog.String("warn", "endpoint is not secure"))
+	}
+
+	return &Client{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		apiKey:   apiKey,
+		client:   http.DefaultClient,
+	}
+}
+
+var _ shortener.Shortener = (*Client)(nil)
+
+// Name implements shortener.Shortener.
+func (c *Client) Name() string {
+	return shortener.NameShlink
+}
+
+const apiBasePath = "/rest/v3"
+
+func (c *Client) do(
+	ctx context.Context,
+	method string,
+	p string,
+	body io.Reader,
+) (*http.Response, error) {
+	if ctx == nil {
+		return nil, errors.New("nil context")
+	}
+
+	u, err := url.Parse(c.endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse endpoint: %w", err)
+	}
+
+	u.Path = path.Join(u.Path, apiBasePath, p)
+
+	log.Debug(
+		"shlink - *client.do",
+		slog.String("action", "url_parse"),
+		slog.String("method", method),
+		slog.Any("parsed_url", u),
+	)
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("could not build request: %w", err)
+	}
+
+	req.Header.Set("X-Api-Key", c.apiKey)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not get response: %w", err)
+	}
+
+	log.Debug(
+		"shlink - *client.do",
+		slog.String("action", "got_response"),
+		slog.Int("resp_status_code", resp.StatusCode),
+		slog.String("resp_status", resp.Status),
+	)
+
+	return resp, nil
+}
+
+// problemResponse is the RFC 7807 error body Shlink returns on failures.
+type problemResponse struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Detail string `json:"detail"`
+	Status int    `json:"status"`
+}
+
+func decodeError(resp *http.Response) error {
+	res := &problemResponse{}
+	err := json.NewDecoder(resp.Body).Decode(res)
+	if err != nil || res.Detail == "" {
+		return fmt.Errorf("unwanted status code: %d (%s)", resp.StatusCode, resp.Status)
+	}
+
+	return fmt.Errorf("unwanted status code: %d (%s): %s", resp.StatusCode, res.Title, res.Detail)
+}
+
+// shortCode returns the short code of a short URL,
+// it also accepts a plain short code.
+func shortCode(shortURL string) string {
+	u, err := url.Parse(shortURL)
+	if err != nil || u.Path == "" {
+		return shortURL
+	}
+
+	return path.Base(u.Path)
+}
@@ -0,0 +1,33 @@
+package shlink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/devusSs/minls/internal/log"
+)
+
+// Delete deletes a short URL (or just its short code).
+func (c *Client) Delete(ctx context.Context, shortURL string) error {
+	if ctx == nil {
+		return errors.New("nil context")
+	}
+
+	code := shortCode(shortURL)
+	log.Debug("shlink - *client.Delete", slog.String("code", code))
+
+	resp, err := c.do(ctx, http.MethodDelete, "/short-urls/"+code, nil)
+	if err != nil {
+		return fmt.Errorf("client.do(): %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return decodeError(resp)
+	}
+
+	return nil
+}
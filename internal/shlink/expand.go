@@ -0,0 +1,83 @@
+package shlink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/devusSs/minls/internal/log"
+	"github.com/devusSs/minls/internal/shortener"
+)
+
+// Expand resolves a short URL (or just its short code) to the long URL.
+func (c *Client) Expand(ctx context.Context, shortURL string) (string, error) {
+	res, err := c.getShortURL(ctx, shortURL)
+	if err != nil {
+		return "", err
+	}
+
+	return res.LongURL, nil
+}
+
+// Stats returns the statistics of a short URL (or just its short code).
+func (c *Client) Stats(ctx context.Context, shortURL string) (*shortener.Stats, error) {
+	res, err := c.getShortURL(ctx, shortURL)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &shortener.Stats{
+		ShortURL: res.ShortURL,
+		LongURL:  res.LongURL,
+		Title:    res.Title,
+		Clicks:   res.VisitsSummary.Total,
+	}
+
+	created, err := time.Parse(time.RFC3339, res.DateCreated)
+	if err == nil {
+		s.Created = created
+	}
+
+	return s, nil
+}
+
+func (c *Client) getShortURL(ctx context.Context, shortURL string) (*shortURLResponse, error) {
+	if ctx == nil {
+		return nil, errors.New("nil context")
+	}
+
+	code := shortCode(shortURL)
+	log.Debug("shlink - *client.getShortURL", slog.String("code", code))
+
+	resp, err := c.do(ctx, http.MethodGet, "/short-urls/"+code, nil)
+	if err != nil {
+		return nil, fmt.Errorf("client.do(): %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, shortener.ErrNotFound
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, decodeError(resp)
+	}
+
+	res := &shortURLResponse{}
+	err = json.NewDecoder(resp.Body).Decode(res)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode response: %w", err)
+	}
+
+	log.Debug(
+		"shlink - *client.getShortURL",
+		slog.String("action", "decoded_resp"),
+		slog.Any("res", res),
+	)
+
+	return res, nil
+}
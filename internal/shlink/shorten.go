@@ -0,0 +1,90 @@
+package shlink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/devusSs/minls/internal/log"
+	"github.com/devusSs/minls/internal/shortener"
+)
+
+// Shorten creates a new short URL for the input URL.
+// If opts does not contain a keyword Shlink generates a short code.
+func (c *Client) Shorten(
+	ctx context.Context,
+	input string,
+	opts *shortener.ShortenOptions,
+) (string, error) {
+	if ctx == nil {
+		return "", errors.New("nil context")
+	}
+
+	if opts == nil {
+		opts = &shortener.ShortenOptions{}
+	}
+
+	r := &shortenRequest{
+		LongURL:      input,
+		CustomSlug:   opts.Keyword,
+		Title:        opts.Title,
+		FindIfExists: true,
+	}
+
+	if r.Title == "" {
+		r.Title = shortener.DefaultTitle
+	}
+
+	log.Debug("shlink - *client.Shorten", slog.String("action", "set_request"), slog.Any("r", r))
+
+	b, err := json.Marshal(r)
+	if err != nil {
+		return "", fmt.Errorf("could not marshal request: %w", err)
+	}
+
+	resp, err := c.do(ctx, http.MethodPost, "/short-urls", bytes.NewReader(b))
+	if err != nil {
+		return "", fmt.Errorf("client.do(): %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", decodeError(resp)
+	}
+
+	res := &shortURLResponse{}
+	err = json.NewDecoder(resp.Body).Decode(res)
+	if err != nil {
+		return "", fmt.Errorf("could not decode response: %w", err)
+	}
+
+	log.Debug(
+		"shlink - *client.Shorten",
+		slog.String("action", "decoded_resp"),
+		slog.Any("res", res),
+	)
+
+	return res.ShortURL, nil
+}
+
+type shortenRequest struct {
+	LongURL      string `json:"longUrl"`
+	CustomSlug   string `json:"customSlug,omitempty"`
+	Title        string `json:"title,omitempty"`
+	FindIfExists bool   `json:"findIfExists"`
+}
+
+type shortURLResponse struct {
+	ShortCode     string `json:"shortCode"`
+	ShortURL      string `json:"shortUrl"`
+	LongURL       string `json:"longUrl"`
+	DateCreated   string `json:"dateCreated"`
+	Title         string `json:"title"`
+	VisitsSummary struct {
+		Total int64 `json:"total"`
+	} `json:"visitsSummary"`
+}
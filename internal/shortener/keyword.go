@@ -0,0 +1,59 @@
+package shortener
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/google/uuid"
+)
+
+const (
+	KeywordStyleUUID   = "uuid"
+	KeywordStyleRandom = "random"
+
+	// DefaultKeywordLength is the length of random keywords if not configured.
+	DefaultKeywordLength = 6
+)
+
+const base62Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// NewKeyword generates a keyword in the specified style,
+// length is only used for KeywordStyleRandom.
+func NewKeyword(style string, length int) (string, error) {
+	switch style {
+	case KeywordStyleUUID:
+		uid, err := uuid.NewUUID()
+		if err != nil {
+			return "", fmt.Errorf("could not create uuid: %w", err)
+		}
+
+		return uid.String(), nil
+	case KeywordStyleRandom:
+		return RandomKeyword(length)
+	default:
+		return "", fmt.Errorf("unknown keyword style: %s", style)
+	}
+}
+
+// RandomKeyword returns a random base62 keyword of the specified length.
+func RandomKeyword(length int) (string, error) {
+	if length <= 0 {
+		return "", errors.New("keyword length must be positive")
+	}
+
+	maxIndex := big.NewInt(int64(len(base62Alphabet)))
+
+	b := make([]byte, length)
+	for i := range b {
+		n, err := rand.Int(rand.Reader, maxIndex)
+		if err != nil {
+			return "", fmt.Errorf("could not read random: %w", err)
+		}
+
+		b[i] = base62Alphabet[n.Int64()]
+	}
+
+	return string(b), nil
+}
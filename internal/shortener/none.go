@@ -0,0 +1,60 @@
+package shortener
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/devusSs/minls/internal/log"
+)
+
+// None is a Shortener which does not shorten at all
+// and returns the provided links unchanged.
+type None struct{}
+
+var _ Shortener = (*None)(nil)
+
+func NewNone() *None {
+	return &None{}
+}
+
+func (n *None) Name() string {
+	return NameNone
+}
+
+func (n *None) Shorten(ctx context.Context, longURL string, _ *ShortenOptions) (string, error) {
+	if ctx == nil {
+		return "", errors.New("nil context")
+	}
+
+	log.Debug("shortener - *None.Shorten", slog.String("action", "return_unchanged"))
+
+	return longURL, nil
+}
+
+// Expand always returns ErrNotFound since None never creates short links,
+// this also means any keyword is considered available.
+func (n *None) Expand(ctx context.Context, _ string) (string, error) {
+	if ctx == nil {
+		return "", errors.New("nil context")
+	}
+
+	return "", ErrNotFound
+}
+
+func (n *None) Delete(ctx context.Context, _ string) error {
+	if ctx == nil {
+		return errors.New("nil context")
+	}
+
+	// nothing was ever created, so there is nothing to delete
+	return nil
+}
+
+func (n *None) Stats(ctx context.Context, _ string) (*Stats, error) {
+	if ctx == nil {
+		return nil, errors.New("nil context")
+	}
+
+	return nil, ErrNotSupported
+}
@@ -0,0 +1,61 @@
+package shortener
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/devusSs/minls/internal/log"
+)
+
+// RedirectCheck is the result of CheckRedirect.
+type RedirectCheck struct {
+	StatusCode int    `json:"status_code"`
+	Location   string `json:"location"`
+	OK         bool   `json:"ok"`
+}
+
+// redirectCheckTimeout limits CheckRedirect if ctx has no deadline.
+const redirectCheckTimeout = 15 * time.Second
+
+// CheckRedirect requests shortURL without following redirects
+// and checks whether it redirects to longURL.
+func CheckRedirect(ctx context.Context, shortURL string, longURL string) (*RedirectCheck, error) {
+	if ctx == nil {
+		return nil, errors.New("nil context")
+	}
+
+	client := &http.Client{
+		Timeout: redirectCheckTimeout,
+		CheckRedirect: func(_ *http.Request, _ []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, shortURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not build request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not get response: %w", err)
+	}
+	defer resp.Body.Close()
+
+	rc := &RedirectCheck{
+		StatusCode: resp.StatusCode,
+		Location:   resp.Header.Get("Location"),
+	}
+
+	rc.OK = rc.StatusCode >= http.StatusMultipleChoices &&
+		rc.StatusCode < http.StatusBadRequest &&
+		rc.Location == longURL
+
+	log.Debug("shortener - CheckRedirect", slog.Any("rc", rc))
+
+	return rc, nil
+}
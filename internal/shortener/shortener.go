@@ -0,0 +1,51 @@
+package shortener
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Shortener is implemented by every URL shortener minls can talk to.
+// All methods accept the full short URL as returned by Shorten,
+// implementations may additionally accept just the keyword / short code.
+type Shortener interface {
+	// Name returns the configuration name of the shortener (e.g. "yourls").
+	Name() string
+	Shorten(ctx context.Context, longURL string, opts *ShortenOptions) (string, error)
+	Expand(ctx context.Context, shortURL string) (string, error)
+	Delete(ctx context.Context, shortURL string) error
+	Stats(ctx context.Context, shortURL string) (*Stats, error)
+}
+
+// ShortenOptions customize a short link.
+// Empty fields fall back to the shortener defaults.
+type ShortenOptions struct {
+	Keyword string `json:"keyword,omitempty"`
+	Title   string `json:"title,omitempty"`
+}
+
+// DefaultTitle is used for short links without a custom title.
+const DefaultTitle = "Uploaded using minls by devusSs"
+
+// Stats contains the statistics of a single short link.
+type Stats struct {
+	ShortURL string    `json:"short_url"`
+	LongURL  string    `json:"long_url"`
+	Title    string    `json:"title,omitempty"`
+	Clicks   int64     `json:"clicks"`
+	Created  time.Time `json:"created,omitzero"`
+}
+
+var (
+	// ErrNotSupported is returned if a shortener does not support an operation.
+	ErrNotSupported = errors.New("operation not supported by shortener")
+	// ErrNotFound is returned if a short link does not exist.
+	ErrNotFound = errors.New("short link not found")
+)
+
+const (
+	NameNone   = "none"
+	NameYOURLS = "yourls"
+	NameShlink = "shlink"
+)
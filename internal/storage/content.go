@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// MaxIndexedContentSize is the largest upload whose text content gets indexed.
+const MaxIndexedContentSize = 64 << 10
+
+// ErrContentNotIndexable is returned for content that is too large or not valid UTF-8.
+var ErrContentNotIndexable = errors.New("content cannot be indexed")
+
+// IndexContent stores the text content of the upload with the entry id
+// so it can be found by search later. The entry has to exist.
+func IndexContent(id int, content []byte) error {
+	if len(content) > MaxIndexedContentSize || !utf8.Valid(content) {
+		return ErrContentNotIndexable
+	}
+
+	return update(func(tx *bolt.Tx) error {
+		_, err := getEntryTx(tx, id)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Bucket(bucketIdxContent).Put(itob(id), content)
+		if err != nil {
+			return fmt.Errorf("could not put content index: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// ReadIndexedContent returns the indexed text content by entry id.
+// Entries without indexed content are missing from the map.
+func ReadIndexedContent() (map[int]string, error) {
+	content := make(map[int]string)
+	err := view(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketIdxContent).ForEach(func(k []byte, v []byte) error {
+			content[btoi(k)] = string(v)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return content, nil
+}
@@ -0,0 +1,417 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+	bolterrors "go.etcd.io/bbolt/errors"
+)
+
+// dbFileName is the name of the database in the storage dir.
+const dbFileName = "minls.db"
+
+// dbFilePath is the path of the database, set by initDB.
+var dbFilePath string
+
+// dbLockTimeout is how long we wait for another minls process
+// to release the database before giving up.
+const dbLockTimeout = 30 * time.Second
+
+// ErrDatabaseLocked is returned if another minls process
+// held the database for longer than dbLockTimeout.
+var ErrDatabaseLocked = errors.New("database is locked by another minls process")
+
+var (
+	bucketEntries      = []byte("entries")
+	bucketIdxTimestamp = []byte("idx_timestamp")
+	bucketIdxKeyword   = []byte("idx_keyword")
+	bucketIdxHash      = []byte("idx_hash")
+	bucketIdxContent   = []byte("idx_content")
+	bucketMeta         = []byte("meta")
+
+	allBuckets = [][]byte{
+		bucketEntries,
+		bucketIdxTimestamp,
+		bucketIdxKeyword,
+		bucketIdxHash,
+		bucketIdxContent,
+		bucketMeta,
+	}
+)
+
+// initDB creates the database and its buckets if they do not exist yet.
+func initDB() error {
+	dbFilePath = filepath.Join(storageDir, dbFileName)
+
+	return update(func(tx *bolt.Tx) error {
+		for _, name := range allBuckets {
+			_, err := tx.CreateBucketIfNotExists(name)
+			if err != nil {
+				return fmt.Errorf("could not create bucket %s: %w", name, err)
+			}
+		}
+
+		return initSchemaVersionTx(tx)
+	})
+}
+
+// update runs fn in a read-write transaction.
+//
+// The database is only opened for the duration of the transaction.
+// bbolt holds an exclusive advisory lock (flock) on the file while it is open,
+// so concurrent minls processes wait for each other here
+// instead of overwriting each others changes.
+// IDs are allocated inside the same transaction, so they stay unique
+// across processes, and bbolt commits are crash safe (copy on write + fsync).
+func update(fn func(tx *bolt.Tx) error) error {
+	db, err := openDB(false)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return db.Update(fn)
+}
+
+// view runs fn in a read-only transaction,
+// multiple processes may read at the same time.
+func view(fn func(tx *bolt.Tx) error) error {
+	db, err := openDB(true)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return db.View(fn)
+}
+
+func openDB(readOnly bool) (*bolt.DB, error) {
+	db, err := bolt.Open(dbFilePath, 0600, &bolt.Options{
+		Timeout:  dbLockTimeout,
+		ReadOnly: readOnly,
+	})
+	if errors.Is(err, bolterrors.ErrTimeout) {
+		return nil, fmt.Errorf("%w (waited %s)", ErrDatabaseLocked, dbLockTimeout)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("could not open %s: %w", dbFilePath, err)
+	}
+
+	return db, nil
+}
+
+func putEntry(entry *DataEntry) error {
+	return update(func(tx *bolt.Tx) error {
+		return putEntryTx(tx, entry)
+	})
+}
+
+// putEntryTx writes the entry and its index keys,
+// entries without an ID get the next free ID.
+func putEntryTx(tx *bolt.Tx, entry *DataEntry) error {
+	entries := tx.Bucket(bucketEntries)
+
+	if entry.ID == 0 {
+		seq, err := entries.NextSequence()
+		if err != nil {
+			return fmt.Errorf("could not get next id: %w", err)
+		}
+
+		entry.ID = int(seq)
+	} else if uint64(entry.ID) > entries.Sequence() {
+		err := entries.SetSequence(uint64(entry.ID))
+		if err != nil {
+			return fmt.Errorf("could not set sequence: %w", err)
+		}
+	}
+
+	old, err := getEntryTx(tx, entry.ID)
+	if err != nil && !errors.Is(err, ErrEntryNotFound) {
+		return err
+	}
+
+	if old != nil {
+		err = deleteIndexesTx(tx, old)
+		if err != nil {
+			return fmt.Errorf("could not delete old indexes: %w", err)
+		}
+	}
+
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("could not marshal entry: %w", err)
+	}
+
+	err = entries.Put(itob(entry.ID), b)
+	if err != nil {
+		return fmt.Errorf("could not put entry: %w", err)
+	}
+
+	return putIndexesTx(tx, entry)
+}
+
+func putIndexesTx(tx *bolt.Tx, entry *DataEntry) error {
+	err := tx.Bucket(bucketIdxTimestamp).Put(timestampKey(entry), nil)
+	if err != nil {
+		return fmt.Errorf("could not put timestamp index: %w", err)
+	}
+
+	if entry.Keyword != "" {
+		err = tx.Bucket(bucketIdxKeyword).Put(keywordKey(entry.Keyword, entry.ID), nil)
+		if err != nil {
+			return fmt.Errorf("could not put keyword index: %w", err)
+		}
+	}
+
+	if entry.Hash != "" {
+		err = tx.Bucket(bucketIdxHash).Put(hashKey(entry.Hash, entry.ID), nil)
+		if err != nil {
+			return fmt.Errorf("could not put hash index: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func deleteIndexesTx(tx *bolt.Tx, entry *DataEntry) error {
+	err := tx.Bucket(bucketIdxTimestamp).Delete(timestampKey(entry))
+	if err != nil {
+		return fmt.Errorf("could not delete timestamp index: %w", err)
+	}
+
+	if entry.Keyword != "" {
+		err = tx.Bucket(bucketIdxKeyword).Delete(keywordKey(entry.Keyword, entry.ID))
+		if err != nil {
+			return fmt.Errorf("could not delete keyword index: %w", err)
+		}
+	}
+
+	if entry.Hash != "" {
+		err = tx.Bucket(bucketIdxHash).Delete(hashKey(entry.Hash, entry.ID))
+		if err != nil {
+			return fmt.Errorf("could not delete hash index: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func getEntryTx(tx *bolt.Tx, id int) (*DataEntry, error) {
+	b := tx.Bucket(bucketEntries).Get(itob(id))
+	if b == nil {
+		return nil, ErrEntryNotFound
+	}
+
+	entry := &DataEntry{}
+	err := json.Unmarshal(b, entry)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshal entry %d: %w", id, err)
+	}
+
+	return entry, nil
+}
+
+// deleteEntry removes the entry with its indexes and indexed content.
+func deleteEntry(id int) error {
+	return update(func(tx *bolt.Tx) error {
+		entry, err := getEntryTx(tx, id)
+		if err != nil {
+			return err
+		}
+
+		err = deleteIndexesTx(tx, entry)
+		if err != nil {
+			return fmt.Errorf("could not delete indexes: %w", err)
+		}
+
+		err = tx.Bucket(bucketIdxContent).Delete(itob(id))
+		if err != nil {
+			return fmt.Errorf("could not delete content index: %w", err)
+		}
+
+		return tx.Bucket(bucketEntries).Delete(itob(id))
+	})
+}
+
+func readEntry(id int) (*DataEntry, error) {
+	var entry *DataEntry
+	err := view(func(tx *bolt.Tx) error {
+		var err error
+		entry, err = getEntryTx(tx, id)
+		return err
+	})
+
+	return entry, err
+}
+
+func updateEntry(id int, fn func(entry *DataEntry) error) error {
+	return update(func(tx *bolt.Tx) error {
+		entry, err := getEntryTx(tx, id)
+		if err != nil {
+			return err
+		}
+
+		err = fn(entry)
+		if err != nil {
+			return err
+		}
+
+		entry.ID = id
+
+		err = entry.validate()
+		if err != nil {
+			return fmt.Errorf("could not validate entry: %w", err)
+		}
+
+		return putEntryTx(tx, entry)
+	})
+}
+
+func readAllEntries() (*Data, error) {
+	data := &Data{Entries: make([]*DataEntry, 0)}
+	err := view(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketEntries).ForEach(func(k []byte, v []byte) error {
+			entry := &DataEntry{}
+			err := json.Unmarshal(v, entry)
+			if err != nil {
+				return fmt.Errorf("could not unmarshal entry %d: %w", btoi(k), err)
+			}
+
+			data.Entries = append(data.Entries, entry)
+
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// findByKeyword returns the newest entry with the keyword,
+// keywords may be reused once their short link was deleted.
+func findByKeyword(keyword string) (*DataEntry, error) {
+	var entry *DataEntry
+	err := view(func(tx *bolt.Tx) error {
+		prefix := keywordKey(keyword, 0)[:len(keyword)+1]
+		c := tx.Bucket(bucketIdxKeyword).Cursor()
+
+		id := 0
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			if len(k) == len(prefix)+idSize {
+				id = btoi(k[len(prefix):])
+			}
+		}
+
+		if id == 0 {
+			return ErrEntryNotFound
+		}
+
+		var err error
+		entry, err = getEntryTx(tx, id)
+		return err
+	})
+
+	return entry, err
+}
+
+func findByHash(hash string) ([]*DataEntry, error) {
+	entries := make([]*DataEntry, 0)
+	err := view(func(tx *bolt.Tx) error {
+		prefix := hashKey(hash, 0)[:len(hash)+1]
+		c := tx.Bucket(bucketIdxHash).Cursor()
+
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			entry, err := getEntryTx(tx, btoi(k[len(prefix):]))
+			if err != nil {
+				return err
+			}
+
+			entries = append(entries, entry)
+		}
+
+		return nil
+	})
+
+	return entries, err
+}
+
+func readRange(since time.Time, until time.Time) ([]*DataEntry, error) {
+	entries := make([]*DataEntry, 0)
+	err := view(func(tx *bolt.Tx) error {
+		c := tx.Bucket(bucketIdxTimestamp).Cursor()
+
+		var limit []byte
+		if !until.IsZero() {
+			limit = timestampKey(&DataEntry{Timestamp: until})
+		}
+
+		start := timestampKey(&DataEntry{Timestamp: since})
+		if since.IsZero() {
+			start = nil
+		}
+
+		k, _ := c.First()
+		if start != nil {
+			k, _ = c.Seek(start)
+		}
+
+		for ; k != nil && (limit == nil || bytes.Compare(k, limit) < 0); k, _ = c.Next() {
+			entry, err := getEntryTx(tx, btoi(k[idSize:]))
+			if err != nil {
+				return err
+			}
+
+			entries = append(entries, entry)
+		}
+
+		return nil
+	})
+
+	return entries, err
+}
+
+const idSize = 8
+
+func itob(id int) []byte {
+	b := make([]byte, idSize)
+	binary.BigEndian.PutUint64(b, uint64(id))
+	return b
+}
+
+func btoi(b []byte) int {
+	return int(binary.BigEndian.Uint64(b))
+}
+
+// timestampKey sorts by timestamp first and id second.
+// Timestamps before the unix epoch are clamped to it.
+func timestampKey(entry *DataEntry) []byte {
+	b := make([]byte, 0, 2*idSize)
+	b = binary.BigEndian.AppendUint64(b, uint64(max(entry.Timestamp.UnixNano(), 0)))
+	return append(b, itob(entry.ID)...)
+}
+
+// keywordKey allows multiple entries per keyword using "<keyword>\x00<id>" keys,
+// unlike hashes keywords may contain slashes.
+func keywordKey(keyword string, id int) []byte {
+	b := make([]byte, 0, len(keyword)+1+idSize)
+	b = append(b, keyword...)
+	b = append(b, 0)
+	return append(b, itob(id)...)
+}
+
+// hashKey allows multiple entries per hash using "<hash>/<id>" keys.
+func hashKey(hash string, id int) []byte {
+	b := make([]byte, 0, len(hash)+1+idSize)
+	b = append(b, hash...)
+	b = append(b, '/')
+	return append(b, itob(id)...)
+}
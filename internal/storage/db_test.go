@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFindByKeywordShared(t *testing.T) {
+	setupDB(t)
+
+	first := &DataEntry{MinioLink: "http://m/1", Keyword: "abc"}
+	second := &DataEntry{MinioLink: "http://m/2", Keyword: "abc"}
+
+	for _, e := range []*DataEntry{first, second} {
+		err := WriteEntry(e)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	steps := []struct {
+		name   string
+		change func() error
+		want   int
+	}{
+		{
+			name:   "newest entry wins",
+			change: func() error { return nil },
+			want:   second.ID,
+		},
+		{
+			name: "updating the older entry keeps the newer indexed",
+			change: func() error {
+				return UpdateEntry(first.ID, func(e *DataEntry) error {
+					e.Title = "changed"
+					return nil
+				})
+			},
+			want: second.ID,
+		},
+		{
+			name:   "deleting the newer entry keeps the older indexed",
+			change: func() error { return DeleteEntry(second.ID) },
+			want:   first.ID,
+		},
+		{
+			name: "changing the keyword removes the entry",
+			change: func() error {
+				return UpdateEntry(first.ID, func(e *DataEntry) error {
+					e.Keyword = "def"
+					return nil
+				})
+			},
+			want: 0,
+		},
+	}
+
+	for _, step := range steps {
+		err := step.change()
+		if err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+
+		entry, err := FindByKeyword("abc")
+
+		switch {
+		case step.want == 0:
+			if !errors.Is(err, ErrEntryNotFound) {
+				t.Errorf("%s: got %v, %v, want %v", step.name, entry, err, ErrEntryNotFound)
+			}
+		case err != nil:
+			t.Errorf("%s: %v", step.name, err)
+		case entry.ID != step.want:
+			t.Errorf("%s: found entry %d, want %d", step.name, entry.ID, step.want)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// ImportReport describes the result of Import.
+type ImportReport struct {
+	DryRun bool `json:"dry_run"`
+	// Added entries were not in the history yet.
+	Added int `json:"added"`
+	// Merged entries were already in the history and got missing fields or tags.
+	Merged int `json:"merged"`
+	// Skipped entries were already in the history unchanged.
+	Skipped int `json:"skipped"`
+	// Renumbered maps imported IDs which were taken to their new ID.
+	Renumbered map[int]int `json:"renumbered,omitempty"`
+	BackupPath string      `json:"backup_path,omitempty"`
+}
+
+// Import merges the entries of data into the history.
+//
+// Entries of the same upload (same object or minio link) are merged,
+// the history wins but missing fields are filled in and tags are combined.
+// New entries keep their ID unless it is taken, then they get the next free one.
+// Data of an older schema version is migrated after importing.
+// If dryRun is set the import is rolled back and only reported.
+func Import(data *Data, dryRun bool) (*ImportReport, error) {
+	version := data.schemaVersion()
+	if version > CurrentSchemaVersion {
+		return nil, fmt.Errorf("%w (%d > %d)", ErrNewerSchema, version, CurrentSchemaVersion)
+	}
+
+	for i, entry := range data.Entries {
+		if entry.MinioLink == "" {
+			return nil, fmt.Errorf("entry %d (id %d) has no minio link", i+1, entry.ID)
+		}
+
+		err := entry.validate()
+		if err != nil {
+			return nil, fmt.Errorf("invalid entry %d (id %d): %w", i+1, entry.ID, err)
+		}
+	}
+
+	report := &ImportReport{DryRun: dryRun, Renumbered: make(map[int]int)}
+
+	if !dryRun {
+		current, err := SchemaVersion()
+		if err != nil {
+			return nil, fmt.Errorf("could not get schema version: %w", err)
+		}
+
+		report.BackupPath, err = backupDB(current)
+		if err != nil {
+			return nil, fmt.Errorf("could not back up database: %w", err)
+		}
+	}
+
+	errDryRun := errors.New("dry run")
+
+	err := update(func(tx *bolt.Tx) error {
+		existing, err := readEntriesTx(tx)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range data.Entries {
+			err = importEntryTx(tx, existing, entry, report)
+			if err != nil {
+				return err
+			}
+
+			existing = append(existing, entry)
+		}
+
+		err = lowerSchemaVersionTx(tx, version)
+		if err != nil {
+			return err
+		}
+
+		if dryRun {
+			// returning an error rolls back the transaction
+			return errDryRun
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, err
+	}
+
+	if !dryRun && version < CurrentSchemaVersion {
+		_, err = Migrate(false)
+		if err != nil {
+			return nil, fmt.Errorf("could not migrate imported entries: %w", err)
+		}
+	}
+
+	return report, nil
+}
+
+func importEntryTx(tx *bolt.Tx, existing []*DataEntry, entry *DataEntry, report *ImportReport) error {
+	i := slices.IndexFunc(existing, func(e *DataEntry) bool { return sameUpload(e, entry) })
+	if i != -1 {
+		current := existing[i]
+		if !mergeEntry(current, entry) {
+			report.Skipped++
+			return nil
+		}
+
+		report.Merged++
+
+		return putEntryTx(tx, current)
+	}
+
+	oldID := entry.ID
+
+	if entry.ID != 0 {
+		_, err := getEntryTx(tx, entry.ID)
+		if err == nil {
+			entry.ID = 0
+		} else if !errors.Is(err, ErrEntryNotFound) {
+			return err
+		}
+	}
+
+	err := putEntryTx(tx, entry)
+	if err != nil {
+		return fmt.Errorf("could not put entry %d: %w", oldID, err)
+	}
+
+	report.Added++
+
+	if oldID != 0 && oldID != entry.ID {
+		report.Renumbered[oldID] = entry.ID
+	}
+
+	return nil
+}
+
+// sameUpload reports whether both entries describe the same uploaded object.
+// Entries of different profiles never match since they live on different
+// backends, an empty profile (entries from before profiles) matches any.
+func sameUpload(a *DataEntry, b *DataEntry) bool {
+	if a.Profile != "" && b.Profile != "" && a.Profile != b.Profile {
+		return false
+	}
+
+	if a.Bucket != "" && a.ObjectKey != "" && b.Bucket != "" && b.ObjectKey != "" {
+		return a.Bucket == b.Bucket && a.ObjectKey == b.ObjectKey
+	}
+
+	return a.MinioLink == b.MinioLink
+}
+
+// mergeEntry fills empty fields of dst from src and combines their tags,
+// it reports whether dst changed.
+func mergeEntry(dst *DataEntry, src *DataEntry) bool {
+	changed := false
+
+	fill := func(d *string, s string) {
+		if *d == "" && s != "" {
+			*d = s
+			changed = true
+		}
+	}
+
+	fill(&dst.Shortener, src.Shortener)
+	fill(&dst.Keyword, src.Keyword)
+	fill(&dst.Title, src.Title)
+	fill(&dst.Hash, src.Hash)
+	fill(&dst.Policy, src.Policy)
+	fill(&dst.Bucket, src.Bucket)
+	fill(&dst.ObjectKey, src.ObjectKey)
+	fill(&dst.OriginalName, src.OriginalName)
+	fill(&dst.ContentType, src.ContentType)
+	fill(&dst.Note, src.Note)
+	fill(&dst.Profile, src.Profile)
+
+	if dst.Size == 0 && src.Size != 0 {
+		dst.Size = src.Size
+		changed = true
+	}
+
+	if dst.ExpiresAt.IsZero() && !src.ExpiresAt.IsZero() {
+		dst.ExpiresAt = src.ExpiresAt
+		changed = true
+	}
+
+	for _, tag := range src.Tags {
+		if !slices.Contains(dst.Tags, tag) {
+			dst.Tags = append(dst.Tags, tag)
+			changed = true
+		}
+	}
+
+	slices.Sort(dst.Tags)
+
+	return changed
+}
@@ -0,0 +1,202 @@
+package storage
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestImport(t *testing.T) {
+	const (
+		link      = "http://minio:9000/minls-private/a.txt?X-Amz-Date=20250101T000000Z&X-Amz-Expires=3600"
+		otherLink = "http://minio:9000/minls-public/b.txt"
+	)
+
+	tests := []struct {
+		name     string
+		existing []*DataEntry
+		data     *Data
+		dryRun   bool
+		report   ImportReport
+		check    func(t *testing.T, entries []*DataEntry)
+	}{
+		{
+			name:   "unversioned data is migrated",
+			data:   &Data{Entries: []*DataEntry{{ID: 1, MinioLink: link, YOURLSLink: "http://s/abc"}}},
+			report: ImportReport{Added: 1},
+			check: func(t *testing.T, entries []*DataEntry) {
+				e := entries[0]
+				if e.Shortener != "yourls" || e.Keyword != "abc" {
+					t.Errorf("shortener %q keyword %q, want yourls abc", e.Shortener, e.Keyword)
+				}
+
+				if e.ExpiresAt.IsZero() {
+					t.Error("expiry not derived")
+				}
+
+				if e.Policy != "private" || e.Bucket != "minls-private" || e.ObjectKey != "a.txt" {
+					t.Errorf("policy %q object %s/%s not derived", e.Policy, e.Bucket, e.ObjectKey)
+				}
+			},
+		},
+		{
+			name: "current data is not migrated",
+			data: &Data{
+				Version: CurrentSchemaVersion,
+				Entries: []*DataEntry{{ID: 1, MinioLink: link, YOURLSLink: "http://s/abc"}},
+			},
+			report: ImportReport{Added: 1},
+			check: func(t *testing.T, entries []*DataEntry) {
+				if entries[0].Shortener != "" || entries[0].Bucket != "" {
+					t.Errorf("entry was migrated: %+v", entries[0])
+				}
+			},
+		},
+		{
+			name:     "same upload is merged",
+			existing: []*DataEntry{{MinioLink: link, Tags: []string{"a"}}},
+			data: &Data{
+				Version: CurrentSchemaVersion,
+				Entries: []*DataEntry{{ID: 7, MinioLink: link, Title: "title", Tags: []string{"b"}}},
+			},
+			report: ImportReport{Merged: 1},
+			check: func(t *testing.T, entries []*DataEntry) {
+				if len(entries) != 1 || entries[0].Title != "title" || !slices.Equal(entries[0].Tags, []string{"a", "b"}) {
+					t.Errorf("entries not merged: %+v", entries)
+				}
+			},
+		},
+		{
+			name:     "unchanged upload is skipped",
+			existing: []*DataEntry{{MinioLink: link, Title: "title"}},
+			data: &Data{
+				Version: CurrentSchemaVersion,
+				Entries: []*DataEntry{{ID: 1, MinioLink: link, Title: "other"}},
+			},
+			report: ImportReport{Skipped: 1},
+			check: func(t *testing.T, entries []*DataEntry) {
+				if entries[0].Title != "title" {
+					t.Errorf("history did not win: %q", entries[0].Title)
+				}
+			},
+		},
+		{
+			name:     "upload of another profile is added",
+			existing: []*DataEntry{{MinioLink: link, Profile: "default"}},
+			data: &Data{
+				Version: CurrentSchemaVersion,
+				Entries: []*DataEntry{{ID: 7, MinioLink: link, Profile: "team"}},
+			},
+			report: ImportReport{Added: 1},
+			check: func(t *testing.T, entries []*DataEntry) {
+				if len(entries) != 2 || entries[1].Profile != "team" {
+					t.Errorf("entry of profile team not added: %+v", entries)
+				}
+			},
+		},
+		{
+			name:     "missing profile is merged",
+			existing: []*DataEntry{{MinioLink: link}},
+			data: &Data{
+				Version: CurrentSchemaVersion,
+				Entries: []*DataEntry{{ID: 7, MinioLink: link, Profile: "team"}},
+			},
+			report: ImportReport{Merged: 1},
+			check: func(t *testing.T, entries []*DataEntry) {
+				if len(entries) != 1 || entries[0].Profile != "team" {
+					t.Errorf("profile not merged: %+v", entries)
+				}
+			},
+		},
+		{
+			name:     "taken id is renumbered",
+			existing: []*DataEntry{{MinioLink: link}},
+			data: &Data{
+				Version: CurrentSchemaVersion,
+				Entries: []*DataEntry{{ID: 1, MinioLink: otherLink}},
+			},
+			report: ImportReport{Added: 1, Renumbered: map[int]int{1: 2}},
+			check: func(t *testing.T, entries []*DataEntry) {
+				if len(entries) != 2 || entries[1].MinioLink != otherLink {
+					t.Errorf("entry not imported as 2: %+v", entries)
+				}
+			},
+		},
+		{
+			name:   "dry run writes nothing",
+			data:   &Data{Entries: []*DataEntry{{ID: 1, MinioLink: link}}},
+			dryRun: true,
+			report: ImportReport{DryRun: true, Added: 1},
+			check: func(t *testing.T, entries []*DataEntry) {
+				if len(entries) != 0 {
+					t.Errorf("dry run imported %d entries", len(entries))
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupDB(t)
+
+			for _, e := range tt.existing {
+				err := WriteEntry(e)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			report, err := Import(tt.data, tt.dryRun)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if report.DryRun != tt.report.DryRun ||
+				report.Added != tt.report.Added ||
+				report.Merged != tt.report.Merged ||
+				report.Skipped != tt.report.Skipped {
+				t.Errorf("report = %+v, want %+v", report, tt.report)
+			}
+
+			for oldID, newID := range tt.report.Renumbered {
+				if report.Renumbered[oldID] != newID {
+					t.Errorf("id %d renumbered to %d, want %d", oldID, report.Renumbered[oldID], newID)
+				}
+			}
+
+			version, err := SchemaVersion()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if version != CurrentSchemaVersion {
+				t.Errorf("schema version = %d after import, want %d", version, CurrentSchemaVersion)
+			}
+
+			data, err := ReadData()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tt.check(t, data.Entries)
+		})
+	}
+}
+
+func TestImportNewerSchema(t *testing.T) {
+	setupDB(t)
+
+	_, err := Import(&Data{Version: CurrentSchemaVersion + 1}, false)
+	if !errors.Is(err, ErrNewerSchema) {
+		t.Errorf("err = %v, want %v", err, ErrNewerSchema)
+	}
+}
+
+func TestImportMissingLink(t *testing.T) {
+	setupDB(t)
+
+	_, err := Import(&Data{Entries: []*DataEntry{{ID: 1}}}, false)
+	if err == nil {
+		t.Error("entry without minio link was imported")
+	}
+}
@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// legacyDataFileName is the JSON file in the storage dir history
+// was stored in before we moved to an embedded database.
+const legacyDataFileName = "minls.data.json"
+
+// legacyDataFilePath is the path of the legacy data file, set by migrateLegacyDataFile.
+var legacyDataFilePath string
+
+// legacyMigratedSuffix is appended to the legacy data file after migrating it.
+const legacyMigratedSuffix = ".migrated"
+
+// migrateLegacyDataFile imports the entries of the legacy JSON data file
+// into the database once and renames the file afterwards.
+// Everything happens while holding the database lock,
+// so concurrent processes cannot import the file twice.
+func migrateLegacyDataFile() error {
+	legacyDataFilePath = filepath.Join(storageDir, legacyDataFileName)
+
+	_, err := os.Stat(legacyDataFilePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return fmt.Errorf("could not os.Stat legacy data file: %w", err)
+	}
+
+	renamed := false
+	err = update(func(tx *bolt.Tx) error {
+		data, err := readLegacyDataFile()
+		if errors.Is(err, os.ErrNotExist) {
+			// another process migrated the file while we waited for the lock
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("could not read legacy data file: %w", err)
+		}
+
+		for _, entry := range data.Entries {
+			err = putEntryTx(tx, entry)
+			if err != nil {
+				return fmt.Errorf("could not put entry %d: %w", entry.ID, err)
+			}
+		}
+
+		// the imported entries may need migrations, even if the database did not
+		err = lowerSchemaVersionTx(tx, data.schemaVersion())
+		if err != nil {
+			return err
+		}
+
+		err = os.Rename(legacyDataFilePath, legacyDataFilePath+legacyMigratedSuffix)
+		if err != nil {
+			return fmt.Errorf("could not rename legacy data file: %w", err)
+		}
+
+		renamed = true
+
+		return nil
+	})
+	if err != nil && renamed {
+		// the commit failed, restore the file so we migrate it next time
+		renameErr := os.Rename(legacyDataFilePath+legacyMigratedSuffix, legacyDataFilePath)
+		if renameErr != nil {
+			return fmt.Errorf("%w (could not restore legacy data file: %w)", err, renameErr)
+		}
+	}
+
+	return err
+}
+
+func readLegacyDataFile() (*Data, error) {
+	f, err := os.Open(legacyDataFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open legacy data file: %w", err)
+	}
+	defer f.Close()
+
+	b, err := io.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("could not read from legacy data file: %w", err)
+	}
+
+	// an empty file was created but never written to
+	if len(b) == 0 {
+		return &Data{}, nil
+	}
+
+	data := &Data{}
+	err = json.Unmarshal(b, data)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"could not unmarshal data (fix or remove %s to continue): %w",
+			legacyDataFilePath,
+			err,
+		)
+	}
+
+	if data.schemaVersion() > CurrentSchemaVersion {
+		return nil, fmt.Errorf(
+			"%w (%d > %d)",
+			ErrNewerSchema,
+			data.schemaVersion(),
+			CurrentSchemaVersion,
+		)
+	}
+
+	return data, nil
+}
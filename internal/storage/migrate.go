@@ -0,0 +1,403 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/devusSs/minls/internal/atomicfile"
+)
+
+// CurrentSchemaVersion is the version of the history format this build writes.
+//
+// Version 1 is the format of the legacy JSON data file (which had no version field),
+// every migration below upgrades the history by exactly one version.
+const CurrentSchemaVersion = 4
+
+// migration upgrades the history from version to version+1.
+// apply is called for every changed entry with a short description
+// of the change, which is used for dry runs.
+type migration struct {
+	version     int
+	description string
+	migrate     func(tx *bolt.Tx, apply func(entry *DataEntry, change string) error) error
+}
+
+// migrations must be sorted by version and must not have gaps.
+var migrations = []*migration{
+	{
+		version:     1,
+		description: "record shortener and keyword of entries created before shorteners were pluggable",
+		migrate:     migrateV1ToV2,
+	},
+	{
+		version:     2,
+		description: "record when the minio link of entries expires",
+		migrate:     migrateV2ToV3,
+	},
+	{
+		version:     3,
+		description: "record policy, bucket and object key of entries",
+		migrate:     migrateV3ToV4,
+	},
+}
+
+// MigrationReport describes a (dry) migration run.
+type MigrationReport struct {
+	FromVersion int              `json:"from_version"`
+	ToVersion   int              `json:"to_version"`
+	DryRun      bool             `json:"dry_run"`
+	BackupPath  string           `json:"backup_path,omitempty"`
+	Steps       []*MigrationStep `json:"steps"`
+}
+
+// MigrationStep describes one migration of a MigrationReport.
+type MigrationStep struct {
+	FromVersion int      `json:"from_version"`
+	ToVersion   int      `json:"to_version"`
+	Description string   `json:"description"`
+	Changes     []string `json:"changes"`
+}
+
+// ErrNewerSchema is returned if the history was written by a newer minls build.
+var ErrNewerSchema = errors.New("history was written by a newer version of minls")
+
+var metaKeySchemaVersion = []byte("schema_version")
+
+// Migrate upgrades the history to CurrentSchemaVersion.
+// Before changing anything a backup of the database is written.
+// If dryRun is set the migrations run in a transaction which is rolled back
+// and the report shows what would change.
+func Migrate(dryRun bool) (*MigrationReport, error) {
+	version, err := SchemaVersion()
+	if err != nil {
+		return nil, fmt.Errorf("could not get schema version: %w", err)
+	}
+
+	report := &MigrationReport{
+		FromVersion: version,
+		ToVersion:   CurrentSchemaVersion,
+		DryRun:      dryRun,
+		Steps:       make([]*MigrationStep, 0),
+	}
+
+	if version > CurrentSchemaVersion {
+		return nil, fmt.Errorf("%w (%d > %d)", ErrNewerSchema, version, CurrentSchemaVersion)
+	}
+
+	if version == CurrentSchemaVersion {
+		return report, nil
+	}
+
+	if !dryRun {
+		report.BackupPath, err = backupDB(version)
+		if err != nil {
+			return nil, fmt.Errorf("could not back up database: %w", err)
+		}
+	}
+
+	errDryRun := errors.New("dry run")
+
+	err = update(func(tx *bolt.Tx) error {
+		// another minls process may have migrated since the version was read,
+		// the version only counts inside the transaction bumping it
+		version, err := schemaVersionTx(tx)
+		if err != nil {
+			return err
+		}
+
+		report.FromVersion = version
+
+		if version > CurrentSchemaVersion {
+			return fmt.Errorf("%w (%d > %d)", ErrNewerSchema, version, CurrentSchemaVersion)
+		}
+
+		for _, m := range migrations {
+			if m.version < version {
+				continue
+			}
+
+			step, err := runMigration(tx, m)
+			if err != nil {
+				return err
+			}
+
+			report.Steps = append(report.Steps, step)
+		}
+
+		if dryRun {
+			// returning an error rolls back the transaction
+			return errDryRun
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+func runMigration(tx *bolt.Tx, m *migration) (*MigrationStep, error) {
+	step := &MigrationStep{
+		FromVersion: m.version,
+		ToVersion:   m.version + 1,
+		Description: m.description,
+		Changes:     make([]string, 0),
+	}
+
+	err := m.migrate(tx, func(entry *DataEntry, change string) error {
+		step.Changes = append(step.Changes, fmt.Sprintf("entry %d: %s", entry.ID, change))
+		return putEntryTx(tx, entry)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not migrate from version %d: %w", m.version, err)
+	}
+
+	err = setSchemaVersionTx(tx, m.version+1)
+	if err != nil {
+		return nil, err
+	}
+
+	return step, nil
+}
+
+// SchemaVersion returns the version of the stored history.
+func SchemaVersion() (int, error) {
+	version := 0
+	err := view(func(tx *bolt.Tx) error {
+		var err error
+		version, err = schemaVersionTx(tx)
+		return err
+	})
+
+	return version, err
+}
+
+func schemaVersionTx(tx *bolt.Tx) (int, error) {
+	v := tx.Bucket(bucketMeta).Get(metaKeySchemaVersion)
+	if v == nil {
+		// databases without a version were created from legacy data
+		return 1, nil
+	}
+
+	version, err := strconv.Atoi(string(v))
+	if err != nil {
+		return 0, fmt.Errorf("could not parse schema version: %w", err)
+	}
+
+	return version, nil
+}
+
+func setSchemaVersionTx(tx *bolt.Tx, version int) error {
+	err := tx.Bucket(bucketMeta).Put(metaKeySchemaVersion, []byte(strconv.Itoa(version)))
+	if err != nil {
+		return fmt.Errorf("could not set schema version: %w", err)
+	}
+
+	return nil
+}
+
+// lowerSchemaVersionTx sets the schema version to version
+// if the current version is higher.
+func lowerSchemaVersionTx(tx *bolt.Tx, version int) error {
+	current, err := schemaVersionTx(tx)
+	if err != nil {
+		return err
+	}
+
+	if version >= current {
+		return nil
+	}
+
+	return setSchemaVersionTx(tx, version)
+}
+
+// initSchemaVersionTx marks new, empty databases as up to date.
+func initSchemaVersionTx(tx *bolt.Tx) error {
+	if tx.Bucket(bucketMeta).Get(metaKeySchemaVersion) != nil {
+		return nil
+	}
+
+	if tx.Bucket(bucketEntries).Stats().KeyN > 0 {
+		return nil
+	}
+
+	return setSchemaVersionTx(tx, CurrentSchemaVersion)
+}
+
+const backupsDir = "backups"
+
+// backupDB writes a consistent copy of the database to the backups dir.
+func backupDB(version int) (string, error) {
+	dir := filepath.Join(storageDir, backupsDir)
+
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return "", fmt.Errorf("could not create backups dir: %w", err)
+	}
+
+	name := fmt.Sprintf(
+		"minls.v%d.%s.db",
+		version,
+		time.Now().Format("2006-01-02_15-04-05"),
+	)
+	backupPath := filepath.Join(dir, name)
+
+	err = view(func(tx *bolt.Tx) error {
+		return atomicfile.Write(backupPath, 0600, func(w io.Writer) error {
+			_, writeErr := tx.WriteTo(w)
+			return writeErr
+		})
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return backupPath, nil
+}
+
+// readEntriesTx returns all entries for migrations.
+func readEntriesTx(tx *bolt.Tx) ([]*DataEntry, error) {
+	entries := make([]*DataEntry, 0)
+
+	err := tx.Bucket(bucketEntries).ForEach(func(_ []byte, v []byte) error {
+		entry := &DataEntry{}
+		err := json.Unmarshal(v, entry)
+		if err != nil {
+			return fmt.Errorf("could not unmarshal entry: %w", err)
+		}
+
+		entries = append(entries, entry)
+
+		return nil
+	})
+
+	return entries, err
+}
+
+// migrateV1ToV2 sets the shortener of old entries to YOURLS (the only shortener
+// before they became pluggable) and derives their keyword from the short link.
+func migrateV1ToV2(tx *bolt.Tx, apply func(entry *DataEntry, change string) error) error {
+	entries, err := readEntriesTx(tx)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		changes := make([]string, 0)
+
+		if entry.Shortener == "" {
+			entry.Shortener = "yourls"
+			changes = append(changes, "set shortener to yourls")
+		}
+
+		if entry.Keyword == "" {
+			u, err := url.Parse(entry.YOURLSLink)
+			if err == nil && u.Path != "" && u.Path != "/" {
+				entry.Keyword = path.Base(u.Path)
+				changes = append(changes, "set keyword to "+entry.Keyword)
+			}
+		}
+
+		if len(changes) == 0 {
+			continue
+		}
+
+		err = apply(entry, strings.Join(changes, ", "))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateV2ToV3 derives the expiry of presigned links from their query.
+func migrateV2ToV3(tx *bolt.Tx, apply func(entry *DataEntry, change string) error) error {
+	entries, err := readEntriesTx(tx)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if !entry.ExpiresAt.IsZero() {
+			continue
+		}
+
+		expiresAt, presigned, parseErr := PresignedExpiry(entry.MinioLink)
+		if parseErr != nil || !presigned {
+			continue
+		}
+
+		entry.ExpiresAt = expiresAt
+
+		err = apply(entry, "set expiry to "+expiresAt.Format(time.RFC3339))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Bucket names of entries created before buckets were configurable.
+const (
+	legacyBucketPublic  = "minls-public"
+	legacyBucketPrivate = "minls-private"
+)
+
+// migrateV3ToV4 derives policy, bucket and key from path style minio links.
+func migrateV3ToV4(tx *bolt.Tx, apply func(entry *DataEntry, change string) error) error {
+	entries, err := readEntriesTx(tx)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.Bucket != "" {
+			continue
+		}
+
+		u, parseErr := url.Parse(entry.MinioLink)
+		if parseErr != nil {
+			continue
+		}
+
+		bucket, key, ok := strings.Cut(strings.TrimPrefix(u.Path, "/"), "/")
+		if !ok {
+			continue
+		}
+
+		switch bucket {
+		case legacyBucketPublic:
+			entry.Policy = "public"
+		case legacyBucketPrivate:
+			entry.Policy = "private"
+		default:
+			// unknown layout (e.g. virtual host style), leave it alone
+			continue
+		}
+
+		entry.Bucket = bucket
+		entry.ObjectKey = key
+
+		err = apply(entry, fmt.Sprintf("set policy to %s, object to %s/%s", entry.Policy, bucket, key))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
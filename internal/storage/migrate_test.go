@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestMigrate(t *testing.T) {
+	const link = "http://minio:9000/minls-public/a.txt"
+
+	tests := []struct {
+		name    string
+		version int
+		dryRun  bool
+		steps   int
+		backup  bool
+		want    int
+		wantErr error
+		check   func(t *testing.T, entry *DataEntry)
+	}{
+		{
+			name:    "legacy history",
+			version: 1,
+			steps:   CurrentSchemaVersion - 1,
+			backup:  true,
+			want:    CurrentSchemaVersion,
+			check: func(t *testing.T, entry *DataEntry) {
+				if entry.Shortener != "yourls" || entry.Keyword != "abc" || entry.Bucket != "minls-public" {
+					t.Errorf("entry not migrated: %+v", entry)
+				}
+			},
+		},
+		{
+			name:    "dry run",
+			version: 1,
+			dryRun:  true,
+			steps:   CurrentSchemaVersion - 1,
+			want:    1,
+			check: func(t *testing.T, entry *DataEntry) {
+				if entry.Shortener != "" || entry.Bucket != "" {
+					t.Errorf("dry run changed the entry: %+v", entry)
+				}
+			},
+		},
+		{
+			name:    "partly migrated",
+			version: 3,
+			steps:   CurrentSchemaVersion - 3,
+			backup:  true,
+			want:    CurrentSchemaVersion,
+			check: func(t *testing.T, entry *DataEntry) {
+				if entry.Shortener != "" || entry.Bucket != "minls-public" {
+					t.Errorf("only migrations from version 3 should run: %+v", entry)
+				}
+			},
+		},
+		{
+			name:    "current",
+			version: CurrentSchemaVersion,
+			want:    CurrentSchemaVersion,
+		},
+		{
+			name:    "newer",
+			version: CurrentSchemaVersion + 1,
+			want:    CurrentSchemaVersion + 1,
+			wantErr: ErrNewerSchema,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupDB(t)
+
+			err := WriteEntry(&DataEntry{MinioLink: link, YOURLSLink: "http://s/abc"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = update(func(tx *bolt.Tx) error { return setSchemaVersionTx(tx, tt.version) })
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			report, err := Migrate(tt.dryRun)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+
+			if err == nil {
+				if report.FromVersion != tt.version || len(report.Steps) != tt.steps {
+					t.Errorf(
+						"report from %d with %d steps, want from %d with %d",
+						report.FromVersion,
+						len(report.Steps),
+						tt.version,
+						tt.steps,
+					)
+				}
+
+				if (report.BackupPath != "") != tt.backup {
+					t.Errorf("backup = %q, want backup %t", report.BackupPath, tt.backup)
+				}
+			}
+
+			version, err := SchemaVersion()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if version != tt.want {
+				t.Errorf("schema version = %d, want %d", version, tt.want)
+			}
+
+			if tt.check != nil {
+				entry, err := ReadEntry(1)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				tt.check(t, entry)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	// RetentionForever never archives entries.
+	RetentionForever = "forever"
+	// RetentionAge archives entries older than Retention.MaxAge.
+	RetentionAge = "age"
+	// RetentionExpiry archives entries once their presigned link expired,
+	// entries with public links are never archived.
+	RetentionExpiry = "expiry"
+)
+
+// Retention decides when entries get archived.
+// Archived entries are kept in the history but hidden by default.
+type Retention struct {
+	Mode   string
+	MaxAge time.Duration
+}
+
+func (r *Retention) String() string {
+	if r.Mode == RetentionAge {
+		return fmt.Sprintf("%dd", int(r.MaxAge/day))
+	}
+
+	return r.Mode
+}
+
+const day = 24 * time.Hour
+
+// DefaultRetention archives entries once their link stopped working.
+var DefaultRetention = &Retention{Mode: RetentionExpiry}
+
+// ParseRetention parses "forever", "expiry" or a number of days (e.g. "30d" or "30").
+func ParseRetention(s string) (*Retention, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+
+	switch s {
+	case "":
+		return DefaultRetention, nil
+	case RetentionForever, RetentionExpiry:
+		return &Retention{Mode: s}, nil
+	}
+
+	days, err := strconv.Atoi(strings.TrimSuffix(s, "d"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid retention %q (options: forever / expiry / <days>d)", s)
+	}
+
+	if days <= 0 {
+		return nil, fmt.Errorf("invalid retention %q, days must be positive", s)
+	}
+
+	return &Retention{Mode: RetentionAge, MaxAge: time.Duration(days) * day}, nil
+}
+
+// retentionEnvKey configures the retention, see ParseRetention.
+const retentionEnvKey = "DATA_RETENTION"
+
+func retentionFromEnv() (*Retention, error) {
+	r, err := ParseRetention(os.Getenv(retentionEnvKey))
+	if err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", retentionEnvKey, err)
+	}
+
+	return r, nil
+}
+
+// expired reports whether the entry should be archived at now.
+func (r *Retention) expired(entry *DataEntry, now time.Time) bool {
+	switch r.Mode {
+	case RetentionAge:
+		return now.Sub(entry.Timestamp) > r.MaxAge
+	case RetentionExpiry:
+		return !entry.ExpiresAt.IsZero() && now.After(entry.ExpiresAt)
+	default:
+		return false
+	}
+}
+
+// ApplyRetention archives all entries the retention considers expired
+// and returns how many entries got archived. Changes are persisted immediately.
+// The write lock is only taken if there is something to archive,
+// so concurrent minls processes do not wait for each other needlessly.
+func ApplyRetention(r *Retention) (int, error) {
+	if r == nil {
+		return 0, errors.New("nil retention")
+	}
+
+	if r.Mode == RetentionForever {
+		return 0, nil
+	}
+
+	now := time.Now()
+
+	var due []*DataEntry
+
+	err := view(func(tx *bolt.Tx) error {
+		var err error
+		due, err = expiredEntriesTx(tx, r, now)
+		return err
+	})
+	if err != nil || len(due) == 0 {
+		return 0, err
+	}
+
+	archived := 0
+
+	err = update(func(tx *bolt.Tx) error {
+		// entries may have changed since the view, e.g. been renewed by another process
+		expired, err := expiredEntriesTx(tx, r, now)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range expired {
+			entry.Archived = true
+			entry.ArchivedAt = now
+
+			err = putEntryTx(tx, entry)
+			if err != nil {
+				return fmt.Errorf("could not archive entry %d: %w", entry.ID, err)
+			}
+		}
+
+		archived = len(expired)
+
+		return nil
+	})
+
+	return archived, err
+}
+
+// expiredEntriesTx returns the entries which are not archived yet but expired at now.
+func expiredEntriesTx(tx *bolt.Tx, r *Retention, now time.Time) ([]*DataEntry, error) {
+	expired := make([]*DataEntry, 0)
+
+	err := tx.Bucket(bucketEntries).ForEach(func(_ []byte, v []byte) error {
+		entry := &DataEntry{}
+		err := json.Unmarshal(v, entry)
+		if err != nil {
+			return fmt.Errorf("could not unmarshal entry: %w", err)
+		}
+
+		if !entry.Archived && r.expired(entry, now) {
+			expired = append(expired, entry)
+		}
+
+		return nil
+	})
+
+	return expired, err
+}
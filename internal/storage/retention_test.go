@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestApplyRetention(t *testing.T) {
+	now := time.Now()
+
+	entries := []*DataEntry{
+		{MinioLink: "http://m/old", Timestamp: now.Add(-40 * day)},
+		{MinioLink: "http://m/expired", Timestamp: now.Add(-2 * day), ExpiresAt: now.Add(-day)},
+		{MinioLink: "http://m/valid", Timestamp: now.Add(-2 * day), ExpiresAt: now.Add(day)},
+		{MinioLink: "http://m/archived", Timestamp: now.Add(-40 * day), Archived: true},
+	}
+
+	tests := []struct {
+		retention string
+		archived  int
+		want      []bool
+	}{
+		{retention: "forever", archived: 0, want: []bool{false, false, false, true}},
+		{retention: "expiry", archived: 1, want: []bool{false, true, false, true}},
+		{retention: "30d", archived: 1, want: []bool{true, false, false, true}},
+		{retention: "1d", archived: 3, want: []bool{true, true, true, true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.retention, func(t *testing.T) {
+			setupDB(t)
+
+			for _, e := range entries {
+				entry := *e
+
+				err := WriteEntry(&entry)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			r, err := ParseRetention(tt.retention)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			archived, err := ApplyRetention(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if archived != tt.archived {
+				t.Errorf("archived %d entries, want %d", archived, tt.archived)
+			}
+
+			// a second run has nothing left to do
+			archived, err = ApplyRetention(r)
+			if err != nil || archived != 0 {
+				t.Errorf("second run archived %d entries (err %v), want 0", archived, err)
+			}
+
+			for i, want := range tt.want {
+				entry, err := ReadEntry(i + 1)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if entry.Archived != want {
+					t.Errorf("%s archived = %t, want %t", entry.MinioLink, entry.Archived, want)
+				}
+			}
+		})
+	}
+}
@@ -1,36 +1,59 @@
 package storage
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"time"
+
+	"github.com/devusSs/minls/internal/paths"
 )
 
+// Init sets up the storage and migrates the history
+// to the current schema version if needed.
 func Init() error {
-	err := createStorageDirIfNotExists()
+	err := InitNoMigrate()
 	if err != nil {
-		return fmt.Errorf("could not create storage dir: %w", err)
+		return err
+	}
+
+	_, err = Migrate(false)
+	if err != nil {
+		return fmt.Errorf("could not migrate data: %w", err)
+	}
+
+	r, err := retentionFromEnv()
+	if err != nil {
+		return err
+	}
+
+	_, err = ApplyRetention(r)
+	if err != nil {
+		return fmt.Errorf("could not apply retention: %w", err)
 	}
 
-	err = createStorageFileIfNotExists()
+	return nil
+}
+
+// InitNoMigrate sets up the storage without migrating the history,
+// use Migrate to do so.
+func InitNoMigrate() error {
+	err := createStorageDirIfNotExists()
 	if err != nil {
-		return fmt.Errorf("could not create storage file: %w", err)
+		return fmt.Errorf("could not create storage dir: %w", err)
 	}
 
-	currentData, err = readCurrentDataFromFile()
+	err = initDB()
 	if err != nil {
-		return fmt.Errorf("could not read current data from storage file: %w", err)
+		return fmt.Errorf("could not init database: %w", err)
 	}
 
-	// TODO: run this in goroutine for performance?
-	err = cleanData()
+	err = migrateLegacyDataFile()
 	if err != nil {
-		return fmt.Errorf("could not clean data: %w", err)
+		return fmt.Errorf("could not migrate legacy data file: %w", err)
 	}
 
 	return nil
@@ -42,54 +65,147 @@ func WriteEntry(entry *DataEntry) error {
 		return fmt.Errorf("could not validate entry: %w", err)
 	}
 
-	currentData.Entries = append(currentData.Entries, entry)
-
-	err = writeCurrentData()
+	err = putEntry(entry)
 	if err != nil {
-		return fmt.Errorf("could not write current data: %w", err)
+		return fmt.Errorf("could not put entry: %w", err)
 	}
 
 	return nil
 }
 
-func RemoveStorageDir() error {
-	err := os.RemoveAll(storageDir)
+// RemoveData deletes the files of the history: the database, its backups
+// and the migrated legacy data file. Other files in the data dir are left alone.
+func RemoveData() error {
+	backups, err := filepath.Glob(filepath.Join(storageDir, backupsDir, "minls.v*.db"))
 	if err != nil {
-		return fmt.Errorf("could not os.RemoveAll: %w", err)
+		return fmt.Errorf("could not find backups: %w", err)
 	}
 
+	files := append([]string{
+		filepath.Join(storageDir, dbFileName),
+		filepath.Join(storageDir, legacyDataFileName+legacyMigratedSuffix),
+	}, backups...)
+
+	for _, f := range files {
+		err = os.Remove(f)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("could not remove %s: %w", f, err)
+		}
+	}
+
+	// fails if something else is stored in there, which is kept
+	_ = os.Remove(filepath.Join(storageDir, backupsDir))
+
 	return nil
 }
 
 func ReadData() (*Data, error) {
-	return readCurrentDataFromFile()
+	data, err := readAllEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	data.Version, err = SchemaVersion()
+	if err != nil {
+		return nil, fmt.Errorf("could not get schema version: %w", err)
+	}
+
+	return data, nil
+}
+
+// ReadEntry returns the entry with the id or ErrEntryNotFound.
+func ReadEntry(id int) (*DataEntry, error) {
+	return readEntry(id)
+}
+
+// FindByKeyword returns the entry with the short link keyword or ErrEntryNotFound.
+func FindByKeyword(keyword string) (*DataEntry, error) {
+	return findByKeyword(keyword)
+}
+
+// ReadRange returns all entries with a timestamp in [since, until)
+// sorted by timestamp. Zero times are unbounded.
+func ReadRange(since time.Time, until time.Time) ([]*DataEntry, error) {
+	return readRange(since, until)
 }
 
+// UpdateEntry loads the entry with the id, calls fn with it
+// and writes it back if fn succeeds. Returns ErrEntryNotFound if the entry does not exist.
+func UpdateEntry(id int, fn func(entry *DataEntry) error) error {
+	return updateEntry(id, fn)
+}
+
+// DeleteEntry removes the entry from the history.
+// Returns ErrEntryNotFound if the entry does not exist.
+func DeleteEntry(id int) error {
+	return deleteEntry(id)
+}
+
+// FindByHash returns all entries of uploads with the file hash.
+func FindByHash(hash string) ([]*DataEntry, error) {
+	return findByHash(hash)
+}
+
+// ErrEntryNotFound is returned if no entry matches a lookup.
+var ErrEntryNotFound = errors.New("entry not found")
+
+// Data is the versioned envelope of the history
+// used by data files and exports.
 type Data struct {
+	Version int          `json:"version,omitempty"`
 	Entries []*DataEntry `json:"entries"`
 }
 
+// schemaVersion returns the version of the data,
+// data without a version was written before versioning existed.
+func (d *Data) schemaVersion() int {
+	if d.Version == 0 {
+		return 1
+	}
+
+	return d.Version
+}
+
 // DataEntry is an entry in our data.
 // When creating a DataEntry it is not required to
 // set an ID or timestamp, they will be set
 // automatically if not provided.
+//
+// YOURLSLink holds the short link of whichever shortener
+// was used (see Shortener), it keeps its name for compatibility
+// with existing data files.
 type DataEntry struct {
 	ID         int       `json:"id"`
 	Timestamp  time.Time `json:"timestamp"`
 	MinioLink  string    `json:"minio_link"`
 	YOURLSLink string    `json:"yourls_link"`
+	Shortener  string    `json:"shortener,omitempty"`
+	Keyword    string    `json:"keyword,omitempty"`
+	Title      string    `json:"title,omitempty"`
+	// Hash is the hex encoded SHA-256 of the uploaded file.
+	Hash string `json:"hash,omitempty"`
+	// Policy is either "public" or "private".
+	Policy       string `json:"policy,omitempty"`
+	Bucket       string `json:"bucket,omitempty"`
+	ObjectKey    string `json:"object_key,omitempty"`
+	OriginalName string `json:"original_name,omitempty"`
+	ContentType  string `json:"content_type,omitempty"`
+	Size         int64  `json:"size,omitempty"`
+	// ExpiresAt is when the minio link expires, zero for public links.
+	ExpiresAt  time.Time `json:"expires_at,omitzero"`
+	Archived   bool      `json:"archived,omitempty"`
+	ArchivedAt time.Time `json:"archived_at,omitzero"`
+	// Tags are also stored as object tags on the uploaded object.
+	Tags []string `json:"tags,omitempty"`
+	Note string   `json:"note,omitempty"`
+	// Profile is the config profile the file was uploaded with,
+	// empty for uploads from before profiles existed (the default profile).
+	Profile string `json:"profile,omitempty"`
 }
 
+// validate checks the entry and sets the timestamp if missing.
+// Missing IDs are assigned when the entry is written.
 func (e *DataEntry) validate() error {
-	if e.ID == 0 {
-		latestID, err := findLatestID()
-		if err != nil {
-			return fmt.Errorf("could not find latest id: %w", err)
-		}
-
-		e.ID = latestID + 1
-	}
-
 	if e.Timestamp.IsZero() {
 		e.Timestamp = time.Now()
 	}
@@ -107,20 +223,20 @@ func (e *DataEntry) validate() error {
 	return nil
 }
 
-var storageDir = "data"
+var storageDir string
 
 func createStorageDirIfNotExists() error {
-	exe, err := os.Executable()
+	var err error
+
+	storageDir, err = paths.Data()
 	if err != nil {
-		return fmt.Errorf("could not find executable: %w", err)
+		return fmt.Errorf("could not find data dir: %w", err)
 	}
 
-	storageDir = filepath.Join(filepath.Dir(exe), storageDir)
-
 	fi, err := os.Stat(storageDir)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return os.Mkdir(storageDir, 0700)
+			return os.MkdirAll(storageDir, 0700)
 		}
 
 		return fmt.Errorf("could not os.Stat storage dir: %w", err)
@@ -133,112 +249,34 @@ func createStorageDirIfNotExists() error {
 	return nil
 }
 
-// TODO: split these into multiple files
-// for performance reasons
-var storageFilePath = "minls.data.json"
-
-func createStorageFileIfNotExists() error {
-	storageFilePath = filepath.Join(storageDir, storageFilePath)
-
-	_, err := os.Stat(storageFilePath)
+// PresignedExpiry returns the time a presigned minio link expires at.
+// The bool is false if the link is not presigned (e.g. public links).
+func PresignedExpiry(link string) (time.Time, bool, error) {
+	u, err := url.Parse(link)
 	if err != nil {
-		if os.IsNotExist(err) {
-			_, err = os.Create(storageFilePath)
-			return err
-		}
-
-		return fmt.Errorf("could not os.Stat storage file: %w", err)
+		return time.Time{}, false, fmt.Errorf("could not parse link: %w", err)
 	}
 
-	return nil
-}
+	q := u.Query()
 
-func readCurrentDataFromFile() (*Data, error) {
-	f, err := os.Open(storageFilePath)
-	if err != nil {
-		return nil, fmt.Errorf("could not open storage file: %w", err)
+	date := q.Get("X-Amz-Date")
+	expires := q.Get("X-Amz-Expires")
+	if date == "" || expires == "" {
+		return time.Time{}, false, nil
 	}
-	defer f.Close()
 
-	b, err := io.ReadAll(f)
+	signed, err := time.Parse(amzDateLayout, date)
 	if err != nil {
-		return nil, fmt.Errorf("could not read from storage file: %w", err)
-	}
-
-	// if len(b) == 0 usually indicates
-	// that this is the first writing to the file,
-	// return default data to prevent json errors later
-	if len(b) == 0 {
-		return &Data{}, nil
+		return time.Time{}, true, fmt.Errorf("could not parse X-Amz-Date: %w", err)
 	}
 
-	data := &Data{}
-	err = json.Unmarshal(b, data)
+	seconds, err := strconv.Atoi(expires)
 	if err != nil {
-		return nil, fmt.Errorf("could not unmarshal data: %w", err)
-	}
-
-	return data, nil
-}
-
-var currentData *Data
-
-const dataEntryMaxAge = 7 * 24 * time.Hour
-
-func cleanData() error {
-	if currentData == nil {
-		return errors.New("current data not set up")
-	}
-
-	newData := &Data{Entries: make([]*DataEntry, 0)}
-	for _, entry := range currentData.Entries {
-		if time.Since(entry.Timestamp) > dataEntryMaxAge {
-			continue
-		}
-
-		newData.Entries = append(newData.Entries, entry)
-	}
-
-	currentData = newData
-
-	return nil
-}
-
-func findLatestID() (int, error) {
-	if currentData == nil {
-		return 0, errors.New("current data not set up")
-	}
-
-	latestID := 0
-	for _, entry := range currentData.Entries {
-		if entry.ID > latestID {
-			latestID = entry.ID
-		}
+		return time.Time{}, true, fmt.Errorf("could not parse X-Amz-Expires: %w", err)
 	}
 
-	return latestID, nil
+	return signed.Add(time.Duration(seconds) * time.Second), true, nil
 }
 
-func writeCurrentData() error {
-	if currentData == nil {
-		return errors.New("current data not set up")
-	}
-
-	f, err := os.Create(storageFilePath)
-	if err != nil {
-		return fmt.Errorf("could not create storage file: %w", err)
-	}
-	defer f.Close()
-
-	b, err := json.Marshal(currentData)
-	if err != nil {
-		return fmt.Errorf("could not marshal current data: %w", err)
-	}
-
-	_, err = f.Write(b)
-	if err != nil {
-		return fmt.Errorf("could not write to storage file: %w", err)
-	}
-
-	return nil
-}
+// amzDateLayout is the layout of the X-Amz-Date query parameter.
+const amzDateLayout = "20060102T150405Z"
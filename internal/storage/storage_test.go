@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/devusSs/minls/internal/paths"
+)
+
+// setupDB creates an empty database in a temporary data dir.
+func setupDB(t *testing.T) {
+	t.Helper()
+
+	t.Setenv(paths.EnvDataDir, t.TempDir())
+
+	err := InitNoMigrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRemoveData(t *testing.T) {
+	setupDB(t)
+
+	foreign := filepath.Join(storageDir, "notes.txt")
+
+	err := os.WriteFile(foreign, []byte("keep me"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	backup, err := backupDB(CurrentSchemaVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = RemoveData()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for path, want := range map[string]bool{
+		dbFilePath: false,
+		backup:     false,
+		foreign:    true,
+		storageDir: true,
+	} {
+		_, err := os.Stat(path)
+		if got := err == nil; got != want {
+			t.Errorf("%s exists = %t, want %t", path, got, want)
+		}
+	}
+}
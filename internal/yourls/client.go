@@ -10,15 +10,19 @@ import (
 	"strings"
 
 	"github.com/devusSs/minls/internal/log"
+	"github.com/devusSs/minls/internal/shortener"
 )
 
 type Client struct {
 	endpoint  string
-	signature string
+	auth      Auth
+	transport *TransportConfig
 	client    *http.Client
 }
 
-func NewClient(endpoint string, signature string) *Client {
+// NewClient creates a new YOURLS client.
+// tc may be nil to use the default HTTP client without retries.
+func NewClient(endpoint string, auth Auth, tc *TransportConfig) (*Client, error) {
 	if !strings.Contains(endpoint, "https://") && !strings.Contains(endpoint, "http://") {
 		log.Warn(
 			"yourls - NewClient",
@@ -38,11 +42,28 @@ func NewClient(endpoint string, signature string) *Client {
 		)
 	}
 
+	client, err := newHTTPClient(tc)
+	if err != nil {
+		return nil, fmt.Errorf("could not create http client: %w", err)
+	}
+
+	if tc == nil {
+		tc = &TransportConfig{}
+	}
+
 	return &Client{
 		endpoint:  endpoint,
-		signature: signature,
-		client:    http.DefaultClient,
-	}
+		auth:      auth,
+		transport: tc,
+		client:    client,
+	}, nil
+}
+
+var _ shortener.Shortener = (*Client)(nil)
+
+// Name implements shortener.Shortener.
+func (c *Client) Name() string {
+	return shortener.NameYOURLS
 }
 
 func (c *Client) do(
@@ -64,22 +85,58 @@ func (c *Client) do(
 
 	log.Debug("yourls - *client.do", slog.String("action", "url_parse"), slog.Any("parsed_url", u))
 
-	_, ok := values["signature"]
-	if !ok {
-		log.Warn(
-			"yourls - *client.do",
-			slog.String("action", "check_values"),
-			slog.String("warn", "signature not in values, adding from client"),
-		)
-		values["signature"] = c.signature
+	if c.auth == nil {
+		return nil, errors.New("nil auth")
 	}
 
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			delay := c.transport.backoff(attempt)
+			log.Warn(
+				"yourls - *client.do",
+				slog.String("warn", "retrying request"),
+				slog.Int("attempt", attempt),
+				slog.Duration("delay", delay),
+			)
+
+			err = sleepContext(ctx, delay)
+			if err != nil {
+				return nil, fmt.Errorf("could not wait for retry: %w", err)
+			}
+		}
+
+		var resp *http.Response
+		resp, err = c.doOnce(ctx, u, values)
+		if attempt >= c.transport.MaxRetries || !shouldRetry(ctx, resp, err) {
+			return resp, err
+		}
+
+		if err == nil {
+			resp.Body.Close()
+		}
+	}
+}
+
+// doOnce performs a single request attempt,
+// authentication is applied per attempt since it may be time based.
+func (c *Client) doOnce(
+	ctx context.Context,
+	u *url.URL,
+	values map[string]string,
+) (*http.Response, error) {
+	err := c.auth.apply(values)
+	if err != nil {
+		return nil, fmt.Errorf("could not apply %s auth: %w", c.auth.Mode(), err)
+	}
+
+	log.Debug("yourls - *client.doOnce", slog.String("action", "apply_auth"), slog.String("mode", c.auth.Mode()))
+
 	vl := url.Values{}
 	for k, v := range values {
 		vl.Set(k, v)
 	}
 
-	log.Debug("yourls - *client.do", slog.String("action", "set_values"), slog.Any("vl", vl))
+	log.Debug("yourls - *client.doOnce", slog.String("action", "set_values"), slog.Any("vl", vl))
 
 	req, err := http.NewRequestWithContext(
 		ctx,
@@ -100,7 +157,7 @@ func (c *Client) do(
 	}
 
 	log.Debug(
-		"yourls - *client.do",
+		"yourls - *client.doOnce",
 		slog.String("action", "got_response"),
 		slog.Int("resp_status_code", resp.StatusCode),
 		slog.String("resp_status", resp.Status),
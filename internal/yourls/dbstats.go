@@ -0,0 +1,53 @@
+package yourls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/devusSs/minls/internal/log"
+)
+
+// DBStats are the totals of a YOURLS instance.
+type DBStats struct {
+	Links  int64 `json:"links"`
+	Clicks int64 `json:"clicks"`
+}
+
+// DBStats returns the totals using the db-stats action,
+// which needs nothing but valid authentication.
+func (c *Client) DBStats(ctx context.Context) (*DBStats, error) {
+	if ctx == nil {
+		return nil, errors.New("nil context")
+	}
+
+	v := make(map[string]string)
+	v["action"] = "db-stats"
+	v["format"] = "json"
+
+	resp, err := c.do(ctx, v)
+	if err != nil {
+		return nil, fmt.Errorf("client.do(): %w", err)
+	}
+	defer resp.Body.Close()
+
+	res := &dbStatsResponse{}
+	err = decodeResponse(resp, res)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debug("yourls - *client.DBStats", slog.String("action", "decoded_resp"), slog.Any("res", res))
+
+	return &DBStats{Links: int64(res.DBStats.TotalLinks), Clicks: int64(res.DBStats.TotalClicks)}, nil
+}
+
+type dbStatsResponse struct {
+	StatusCode jsonInt `json:"statusCode"`
+	Message    string  `json:"message"`
+	DBStats    struct {
+		TotalLinks  jsonInt `json:"total_links"`
+		TotalClicks jsonInt `json:"total_clicks"`
+	} `json:"db-stats"`
+}
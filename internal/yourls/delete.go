@@ -0,0 +1,36 @@
+package yourls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/devusSs/minls/internal/log"
+)
+
+// Delete deletes a short URL (or just its keyword).
+//
+// YOURLS does not support deleting links via its API out of the box,
+// this requires the "API Delete" plugin which adds the delete action.
+// Without it shortener.ErrNotSupported is returned.
+func (c *Client) Delete(ctx context.Context, shortURL string) error {
+	if ctx == nil {
+		return errors.New("nil context")
+	}
+
+	v := make(map[string]string)
+	v["action"] = "delete"
+	v["format"] = "json"
+	v["shorturl"] = shortURL
+
+	log.Debug("yourls - *client.Delete", slog.String("action", "set_values"), slog.Any("v", v))
+
+	resp, err := c.do(ctx, v)
+	if err != nil {
+		return fmt.Errorf("client.do(): %w", err)
+	}
+	defer resp.Body.Close()
+
+	return decodeResponse(resp, nil)
+}
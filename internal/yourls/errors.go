@@ -0,0 +1,175 @@
+package yourls
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/devusSs/minls/internal/log"
+	"github.com/devusSs/minls/internal/shortener"
+)
+
+var (
+	// ErrKeywordExists is returned if the requested keyword is taken or reserved.
+	ErrKeywordExists = errors.New("keyword already exists")
+	// ErrURLExists is returned if the URL has already been shortened
+	// and the YOURLS instance does not allow duplicates.
+	ErrURLExists = errors.New("url already exists")
+	// ErrAuthFailed is returned if YOURLS rejected our credentials.
+	ErrAuthFailed = errors.New("authentication failed")
+	// ErrRateLimited is returned if YOURLS rejected the request because of flooding.
+	ErrRateLimited = errors.New("rate limited")
+)
+
+// APIError is an error reported by the YOURLS API.
+// Use errors.Is with the Err* variables (or shortener.ErrNotFound)
+// to check for specific failures.
+type APIError struct {
+	HTTPStatus int
+	StatusCode int
+	Code       string
+	Message    string
+	err        error
+}
+
+func (e *APIError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.HTTPStatus)
+	}
+
+	if e.Code != "" {
+		return fmt.Sprintf("yourls: %s (status %d, code %s)", msg, e.StatusCode, e.Code)
+	}
+
+	return fmt.Sprintf("yourls: %s (status %d)", msg, e.StatusCode)
+}
+
+func (e *APIError) Unwrap() error {
+	return e.err
+}
+
+// apiResponse contains the fields every YOURLS response may carry.
+type apiResponse struct {
+	Status     string  `json:"status"`
+	Code       string  `json:"code"`
+	Message    string  `json:"message"`
+	StatusCode jsonInt `json:"statusCode"`
+	ErrorCode  jsonInt `json:"errorCode"`
+}
+
+const (
+	codeKeywordExists = "error:keyword"
+	codeURLExists     = "error:url"
+	statusFail        = "fail"
+)
+
+// decodeResponse decodes the body of resp into target (if not nil)
+// and returns an *APIError if YOURLS reported a failure.
+// target is decoded even on failures since some failures
+// (e.g. ErrURLExists) still carry useful data.
+func decodeResponse(resp *http.Response, target any) error {
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("could not read response: %w", err)
+	}
+
+	res := &apiResponse{}
+	decodeErr := json.Unmarshal(b, res)
+
+	log.Debug(
+		"yourls - decodeResponse",
+		slog.Int("resp_status_code", resp.StatusCode),
+		slog.Any("res", res),
+		slog.Any("decode_err", decodeErr),
+	)
+
+	apiErr := res.toError(resp.StatusCode)
+	if apiErr != nil {
+		if target != nil {
+			// best effort, the error is what matters here
+			_ = json.Unmarshal(b, target)
+		}
+
+		return apiErr
+	}
+
+	if decodeErr != nil {
+		return fmt.Errorf("could not decode response: %w", decodeErr)
+	}
+
+	if target == nil {
+		return nil
+	}
+
+	err = json.Unmarshal(b, target)
+	if err != nil {
+		return fmt.Errorf("could not decode response: %w", err)
+	}
+
+	return nil
+}
+
+func (r *apiResponse) toError(httpStatus int) *APIError {
+	status := int(r.StatusCode)
+	if status == 0 {
+		status = int(r.ErrorCode)
+	}
+
+	if status == 0 {
+		status = httpStatus
+	}
+
+	failed := r.Status == statusFail ||
+		httpStatus >= http.StatusBadRequest ||
+		status >= http.StatusBadRequest
+	if !failed {
+		return nil
+	}
+
+	e := &APIError{
+		HTTPStatus: httpStatus,
+		StatusCode: status,
+		Code:       r.Code,
+		Message:    r.Message,
+	}
+
+	switch {
+	case r.Code == codeKeywordExists:
+		e.err = ErrKeywordExists
+	case r.Code == codeURLExists:
+		e.err = ErrURLExists
+	case isStatus(httpStatus, status, http.StatusUnauthorized, http.StatusForbidden):
+		e.err = ErrAuthFailed
+	case isStatus(httpStatus, status, http.StatusTooManyRequests),
+		strings.Contains(strings.ToLower(r.Message), "too fast"):
+		e.err = ErrRateLimited
+	case isStatus(httpStatus, status, http.StatusNotFound):
+		e.err = shortener.ErrNotFound
+	case isStatus(httpStatus, status, http.StatusBadRequest) && isUnknownAction(r.Message):
+		e.err = shortener.ErrNotSupported
+	}
+
+	return e
+}
+
+func isStatus(httpStatus int, status int, wanted ...int) bool {
+	for _, w := range wanted {
+		if httpStatus == w || status == w {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isUnknownAction reports whether YOURLS rejected the action itself,
+// e.g. "Unknown or missing action" if the plugin providing it is not installed.
+func isUnknownAction(msg string) bool {
+	msg = strings.ToLower(msg)
+	return strings.Contains(msg, "unknown") && strings.Contains(msg, "action")
+}
@@ -0,0 +1,67 @@
+package yourls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/devusSs/minls/internal/log"
+	"github.com/devusSs/minls/internal/shortener"
+)
+
+// Expand resolves a short URL (or just its keyword) to the long URL.
+func (c *Client) Expand(ctx context.Context, shortURL string) (string, error) {
+	res, err := c.expand(ctx, shortURL)
+	if err != nil {
+		return "", err
+	}
+
+	return res.Longurl, nil
+}
+
+func (c *Client) expand(ctx context.Context, shortURL string) (*expandResponse, error) {
+	if ctx == nil {
+		return nil, errors.New("nil context")
+	}
+
+	v := make(map[string]string)
+	v["action"] = "expand"
+	v["format"] = "json"
+	v["shorturl"] = shortURL
+
+	log.Debug("yourls - *client.Expand", slog.String("action", "set_values"), slog.Any("v", v))
+
+	resp, err := c.do(ctx, v)
+	if err != nil {
+		return nil, fmt.Errorf("client.do(): %w", err)
+	}
+	defer resp.Body.Close()
+
+	res := &expandResponse{}
+	err = decodeResponse(resp, res)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debug(
+		"yourls - *client.Expand",
+		slog.String("action", "decoded_resp"),
+		slog.Any("res", res),
+	)
+
+	if res.Longurl == "" {
+		return nil, fmt.Errorf("%w: no long url returned: %s", shortener.ErrNotFound, res.Message)
+	}
+
+	return res, nil
+}
+
+type expandResponse struct {
+	Keyword    string  `json:"keyword"`
+	Shorturl   string  `json:"shorturl"`
+	Longurl    string  `json:"longurl"`
+	Title      string  `json:"title"`
+	Message    string  `json:"message"`
+	StatusCode jsonInt `json:"statusCode"`
+}
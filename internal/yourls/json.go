@@ -0,0 +1,39 @@
+package yourls
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// jsonInt is an integer which YOURLS sometimes encodes
+// as a JSON number and sometimes as a JSON string.
+type jsonInt int64
+
+func (i *jsonInt) UnmarshalJSON(b []byte) error {
+	var n json.Number
+	err := json.Unmarshal(b, &n)
+	if err != nil {
+		var s string
+		err = json.Unmarshal(b, &s)
+		if err != nil {
+			return fmt.Errorf("could not unmarshal int: %w", err)
+		}
+
+		n = json.Number(s)
+	}
+
+	if n == "" {
+		*i = 0
+		return nil
+	}
+
+	v, err := strconv.ParseInt(n.String(), 10, 64)
+	if err != nil {
+		return fmt.Errorf("could not parse int: %w", err)
+	}
+
+	*i = jsonInt(v)
+
+	return nil
+}
@@ -2,40 +2,54 @@ package yourls
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
-	"net/http"
-
-	"github.com/google/uuid"
 
 	"github.com/devusSs/minls/internal/log"
+	"github.com/devusSs/minls/internal/shortener"
 )
 
-func (c *Client) Shorten(ctx context.Context, input string) (string, error) {
+// Shorten creates a new short URL for the input URL.
+// If opts does not contain a keyword YOURLS generates one,
+// unless retries are enabled. In that case a random keyword is generated
+// so a retried request cannot create a duplicate short URL.
+func (c *Client) Shorten(
+	ctx context.Context,
+	input string,
+	opts *shortener.ShortenOptions,
+) (string, error) {
 	if ctx == nil {
 		return "", errors.New("nil context")
 	}
 
-	uid, err := uuid.NewUUID()
-	if err != nil {
-		return "", fmt.Errorf("could not create uuid for keyword: %w", err)
+	if opts == nil {
+		opts = &shortener.ShortenOptions{}
 	}
 
-	log.Debug(
-		"yourls - *client.Shorten",
-		slog.String("action", "uuid_newuuid"),
-		slog.Any("uid", uid),
-	)
+	keyword := opts.Keyword
+	if keyword == "" && c.transport.MaxRetries > 0 {
+		var err error
+		keyword, err = shortener.RandomKeyword(shortener.DefaultKeywordLength)
+		if err != nil {
+			return "", fmt.Errorf("could not generate keyword: %w", err)
+		}
+	}
+
+	title := opts.Title
+	if title == "" {
+		title = shortener.DefaultTitle
+	}
 
 	v := make(map[string]string)
-	v["signature"] = c.signature
 	v["action"] = "shorturl"
 	v["format"] = "json"
 	v["url"] = input
-	v["title"] = defaultUploadTitle
-	v["keyword"] = uid.String()
+	v["title"] = title
+
+	if keyword != "" {
+		v["keyword"] = keyword
+	}
 
 	log.Debug("yourls - *client.Shorten", slog.String("action", "set_values"), slog.Any("v", v))
 
@@ -52,26 +66,61 @@ func (c *Client) Shorten(ctx context.Context, input string) (string, error) {
 		slog.String("resp_status", resp.Status),
 	)
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("unwanted status code: %d (%s)", resp.StatusCode, resp.Status)
-	}
-
 	res := &shortenURLResponse{}
-	err = json.NewDecoder(resp.Body).Decode(res)
-	if err != nil {
-		return "", fmt.Errorf("could not decode response: %w", err)
-	}
+	err = decodeResponse(resp, res)
 
 	log.Debug(
 		"yourls - *client.Shorten",
 		slog.String("action", "decoded_resp"),
 		slog.Any("res", res),
+		slog.Any("err", err),
 	)
 
+	if errors.Is(err, ErrURLExists) && res.Shorturl != "" {
+		log.Info(
+			"yourls - *client.Shorten",
+			slog.String("info", "url already shortened, using existing short url"),
+			slog.String("shorturl", res.Shorturl),
+		)
+		return res.Shorturl, nil
+	}
+
+	if errors.Is(err, ErrKeywordExists) && keyword != "" {
+		return c.existingShortURL(ctx, keyword, input, err)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
 	return res.Shorturl, nil
 }
 
-const defaultUploadTitle = "Uploaded using minls by devusSs"
+// existingShortURL returns the short URL of keyword if it already points to input.
+// This happens if a previous (retried) attempt succeeded but its response got lost.
+func (c *Client) existingShortURL(
+	ctx context.Context,
+	keyword string,
+	input string,
+	keywordErr error,
+) (string, error) {
+	res, err := c.expand(ctx, keyword)
+	if err != nil {
+		return "", fmt.Errorf("%w (could not expand existing keyword: %w)", keywordErr, err)
+	}
+
+	if res.Longurl != input {
+		return "", keywordErr
+	}
+
+	log.Info(
+		"yourls - *client.existingShortURL",
+		slog.String("info", "keyword already points to url, using existing short url"),
+		slog.String("shorturl", res.Shorturl),
+	)
+
+	return res.Shorturl, nil
+}
 
 type shortenURLResponse struct {
 	URL struct {
@@ -81,9 +130,9 @@ type shortenURLResponse struct {
 		Date    string `json:"date"`
 		IP      string `json:"ip"`
 	} `json:"url"`
-	Status     string `json:"status"`
-	Message    string `json:"message"`
-	Title      string `json:"title"`
-	Shorturl   string `json:"shorturl"`
-	StatusCode string `json:"statusCode"`
+	Status     string  `json:"status"`
+	Message    string  `json:"message"`
+	Title      string  `json:"title"`
+	Shorturl   string  `json:"shorturl"`
+	StatusCode jsonInt `json:"statusCode"`
 }
@@ -0,0 +1,81 @@
+package yourls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/devusSs/minls/internal/log"
+	"github.com/devusSs/minls/internal/shortener"
+)
+
+// Stats returns the statistics of a short URL (or just its keyword)
+// using the url-stats action.
+func (c *Client) Stats(ctx context.Context, shortURL string) (*shortener.Stats, error) {
+	if ctx == nil {
+		return nil, errors.New("nil context")
+	}
+
+	v := make(map[string]string)
+	v["action"] = "url-stats"
+	v["format"] = "json"
+	v["shorturl"] = shortURL
+
+	log.Debug("yourls - *client.Stats", slog.String("action", "set_values"), slog.Any("v", v))
+
+	resp, err := c.do(ctx, v)
+	if err != nil {
+		return nil, fmt.Errorf("client.do(): %w", err)
+	}
+	defer resp.Body.Close()
+
+	res := &urlStatsResponse{}
+	err = decodeResponse(resp, res)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debug(
+		"yourls - *client.Stats",
+		slog.String("action", "decoded_resp"),
+		slog.Any("res", res),
+	)
+
+	return res.Link.toStats(), nil
+}
+
+type urlStatsResponse struct {
+	StatusCode jsonInt   `json:"statusCode"`
+	Message    string    `json:"message"`
+	Link       statsLink `json:"link"`
+}
+
+type statsLink struct {
+	Shorturl  string  `json:"shorturl"`
+	URL       string  `json:"url"`
+	Title     string  `json:"title"`
+	Timestamp string  `json:"timestamp"`
+	IP        string  `json:"ip"`
+	Clicks    jsonInt `json:"clicks"`
+}
+
+// timestampLayout is the layout YOURLS uses for timestamps in its API responses.
+const timestampLayout = "2006-01-02 15:04:05"
+
+func (l statsLink) toStats() *shortener.Stats {
+	s := &shortener.Stats{
+		ShortURL: l.Shorturl,
+		LongURL:  l.URL,
+		Title:    l.Title,
+		Clicks:   int64(l.Clicks),
+	}
+
+	created, err := time.Parse(timestampLayout, l.Timestamp)
+	if err == nil {
+		s.Created = created
+	}
+
+	return s
+}
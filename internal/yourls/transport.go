@@ -0,0 +1,179 @@
+package yourls
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/devusSs/minls/internal/log"
+)
+
+// TransportConfig configures the HTTP transport of the client.
+// The zero value uses no timeout, no retries and the system defaults.
+type TransportConfig struct {
+	// Timeout limits every single request attempt, 0 means no timeout.
+	Timeout time.Duration
+	// MaxRetries is the number of retries on connection errors and 5xx responses.
+	MaxRetries int
+	// RetryBaseDelay is the base for the exponential backoff between retries.
+	RetryBaseDelay time.Duration
+	// RetryMaxDelay caps the backoff between retries.
+	RetryMaxDelay time.Duration
+	// ProxyURL is the HTTP(S) proxy to use,
+	// defaults to the HTTP_PROXY / HTTPS_PROXY / NO_PROXY environment variables.
+	ProxyURL string
+	// CABundle is the path to a PEM file with additional trusted CAs.
+	CABundle string
+	// PinnedCertSHA256 is the hex encoded SHA-256 fingerprint of the server certificate.
+	// If set, only this certificate is accepted and the CA chain is not verified,
+	// which allows self-signed certificates.
+	PinnedCertSHA256 string
+}
+
+const (
+	DefaultTimeout        = 30 * time.Second
+	DefaultMaxRetries     = 3
+	DefaultRetryBaseDelay = 500 * time.Millisecond
+	DefaultRetryMaxDelay  = 10 * time.Second
+)
+
+func newHTTPClient(tc *TransportConfig) (*http.Client, error) {
+	if tc == nil {
+		return http.DefaultClient, nil
+	}
+
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return nil, errors.New("unexpected default transport type")
+	}
+
+	transport = transport.Clone()
+
+	if tc.ProxyURL != "" {
+		proxy, err := url.Parse(tc.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse proxy url: %w", err)
+		}
+
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	tlsConfig, err := tc.tlsConfig()
+	if err != nil {
+		return nil, fmt.Errorf("could not build tls config: %w", err)
+	}
+
+	transport.TLSClientConfig = tlsConfig
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   tc.Timeout,
+	}, nil
+}
+
+func (tc *TransportConfig) tlsConfig() (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if tc.CABundle != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			log.Warn(
+				"yourls - *TransportConfig.tlsConfig",
+				slog.String("warn", "could not load system cert pool, using empty pool"),
+				slog.Any("err", err),
+			)
+			pool = x509.NewCertPool()
+		}
+
+		pem, err := os.ReadFile(tc.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("could not read ca bundle: %w", err)
+		}
+
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in ca bundle %s", tc.CABundle)
+		}
+
+		cfg.RootCAs = pool
+	}
+
+	if tc.PinnedCertSHA256 != "" {
+		pin, err := hex.DecodeString(strings.ReplaceAll(tc.PinnedCertSHA256, ":", ""))
+		if err != nil {
+			return nil, fmt.Errorf("could not decode pinned certificate fingerprint: %w", err)
+		}
+
+		// the chain is not verified, the pin below replaces that verification
+		cfg.InsecureSkipVerify = true //nolint:gosec // verified by VerifyConnection
+		cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+			if len(cs.PeerCertificates) == 0 {
+				return errors.New("no peer certificates")
+			}
+
+			sum := sha256.Sum256(cs.PeerCertificates[0].Raw)
+			if !strings.EqualFold(hex.EncodeToString(sum[:]), hex.EncodeToString(pin)) {
+				return errors.New("server certificate does not match pinned certificate")
+			}
+
+			return nil
+		}
+	}
+
+	return cfg, nil
+}
+
+// shouldRetry reports whether a request should be retried
+// after the response / error.
+func shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		// a done context is final, everything else
+		// is treated as a (temporary) connection error
+		return ctx.Err() == nil
+	}
+
+	return resp.StatusCode >= http.StatusInternalServerError
+}
+
+// backoff returns the delay before the retry attempt (starting at 1)
+// using exponential backoff with full jitter.
+func (tc *TransportConfig) backoff(attempt int) time.Duration {
+	base := tc.RetryBaseDelay
+	if base <= 0 {
+		base = DefaultRetryBaseDelay
+	}
+
+	maxDelay := tc.RetryMaxDelay
+	if maxDelay <= 0 {
+		maxDelay = DefaultRetryMaxDelay
+	}
+
+	d := base << (attempt - 1)
+	if d <= 0 || d > maxDelay {
+		d = maxDelay
+	}
+
+	return rand.N(d) //nolint:gosec // jitter does not need a secure random source
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -29,6 +29,14 @@ func init() {
 }
 
 func main() {
+	args, err := cli.ParseGlobalFlags(os.Args)
+	if err != nil {
+		fmt.Println("ERROR:", err)
+		os.Exit(1)
+	}
+
+	os.Args = args
+
 	if len(os.Args) > 1 && os.Args[1] == "version" {
 		printVersion()
 		os.Exit(0)
@@ -70,6 +78,20 @@ func handleCommandLine() int {
 	// this function is invoked
 	command := os.Args[1]
 	switch command {
+	case "init":
+		err := cli.Init()
+		if err != nil {
+			fmt.Println("MAIN: INIT FAILED:", err)
+			return 1
+		}
+		return 0
+	case "doctor":
+		err := cli.Doctor()
+		if err != nil {
+			fmt.Println("MAIN: DOCTOR FAILED:", err)
+			return 1
+		}
+		return 0
 	case "list":
 		err := cli.List()
 		if err != nil {
@@ -84,11 +106,82 @@ func handleCommandLine() int {
 			return 1
 		}
 		return 0
+	case "stats":
+		err := cli.Stats()
+		if err != nil {
+			fmt.Println("MAIN: STATS FAILED:", err)
+			return 1
+		}
+		return 0
+	case "info":
+		err := cli.Info()
+		if err != nil {
+			fmt.Println("MAIN: INFO FAILED:", err)
+			return 1
+		}
+		return 0
+	case "search":
+		err := cli.Search()
+		if err != nil {
+			fmt.Println("MAIN: SEARCH FAILED:", err)
+			return 1
+		}
+		return 0
+	case "tag":
+		err := cli.Tag()
+		if err != nil {
+			fmt.Println("MAIN: TAG FAILED:", err)
+			return 1
+		}
+		return 0
+	case "reconcile":
+		err := cli.Reconcile()
+		if err != nil {
+			fmt.Println("MAIN: RECONCILE FAILED:", err)
+			return 1
+		}
+		return 0
+	case "config":
+		err := cli.Config()
+		if err != nil {
+			fmt.Println("MAIN: CONFIG FAILED:", err)
+			return 1
+		}
+		return 0
+	case "lookup":
+		err := cli.Lookup()
+		if err != nil {
+			fmt.Println("MAIN: LOOKUP FAILED:", err)
+			return 1
+		}
+		return 0
 	case "download":
-		fmt.Println("download command, not implemented")
+		err := cli.Download()
+		if err != nil {
+			fmt.Println("MAIN: DOWNLOAD FAILED:", err)
+			return 1
+		}
 		return 0
 	case "delete":
-		fmt.Println("delete command, not implemented")
+		err := cli.Delete()
+		if err != nil {
+			fmt.Println("MAIN: DELETE FAILED:", err)
+			return 1
+		}
+		return 0
+	case "renew":
+		err := cli.Renew()
+		if err != nil {
+			fmt.Println("MAIN: RENEW FAILED:", err)
+			return 1
+		}
+		return 0
+	case "data":
+		err := cli.Data()
+		if err != nil {
+			fmt.Println("MAIN: DATA FAILED:", err)
+			return 1
+		}
 		return 0
 	case "clear":
 		err := cli.Clear()
@@ -119,20 +212,110 @@ func printHelp() {
 	fmt.Println("	minls version				Prints version / build information")
 	fmt.Println("	minls help				Prints this help message")
 	fmt.Println(
-		"	minls list				Prints all uploaded files if possible and available",
+		"	minls init				Sets up minio and yourls of the profile interactively (validates and creates buckets)",
+	)
+	fmt.Println(
+		"	minls doctor [--json]			Checks config, endpoints, credentials, buckets, yourls, clipboard and dirs",
+	)
+	fmt.Println(
+		"	minls list [flags]			Prints all uploaded files if possible and available (--all includes archived)",
+	)
+	fmt.Println(
+		"		[--since] [--until] [--policy] [--type] [--name] [--sort] [--reverse] [--columns]",
+	)
+	fmt.Println(
+		"		[--limit] [--page] [--format table / json / csv / markdown]",
 	)
 	fmt.Println(
 		"	minls upload <filepath> <policy>	Uploads the specified file (policies: private / public)",
 	)
 	fmt.Println(
-		"	minls download <id> <custom_name>	Downloads the specified file to the specified name (in the downloads directory)",
+		"		[--keyword <keyword>] [--keyword-suffix] [--title <title>] [--index]",
+	)
+	fmt.Println(
+		"		[--tag <tag>]... [--note <note>]",
+	)
+	fmt.Println(
+		"	minls stats [id] [--json] [--top <n>]	Prints click statistics of all or one upload(s)",
+	)
+	fmt.Println(
+		"	minls info <id> [--json]		Prints details and the live remote status of an upload",
+	)
+	fmt.Println(
+		"	minls search <query>			Fuzzy searches names, titles, tags, notes, keywords, types and indexed content",
+	)
+	fmt.Println(
+		"		[--all] [--no-content] [--limit <n>] [--json]",
+	)
+	fmt.Println(
+		"	minls tag <id> add / remove <tag>...	Adds or removes tags of an upload (history and minio)",
+	)
+	fmt.Println(
+		"	minls reconcile [--dry-run]		Syncs tags / notes from minio and recovers missing entries",
+	)
+	fmt.Println(
+		"	minls lookup <short-url-or-keyword>	Resolves a short link back to its upload",
+	)
+	fmt.Println(
+		"	minls download <id>			Downloads the file of the entry from the minio of its profile [--out <path>] [--force]",
+	)
+	fmt.Println(
+		"	minls delete <id>			Deletes the short link, file and entry [--keep-object] [--keep-link]",
+	)
+	fmt.Println(
+		"	minls renew <id>			Creates a new presigned link for a private entry and re-points its short link",
+	)
+	fmt.Println(
+		"	minls data migrate [--dry-run]		Migrates the upload history to the current format",
 	)
 	fmt.Println(
-		"	minls delete <id>			Deletes the specified file and shortened link on the remote storage",
+		"	minls data export <file|->		Exports the upload history [--format json / csv / ndjson] [--fields a,b]",
+	)
+	fmt.Println(
+		"	minls data import <file|->		Merges an exported history [--format json / ndjson] [--dry-run]",
+	)
+	fmt.Println(
+		"	minls data import --from-minio	Rebuilds the history from the objects in minio [--dry-run]",
+	)
+	fmt.Println(
+		"	minls config show [--all] [--json]	Prints the effective config with the source of each key",
+	)
+	fmt.Println(
+		"	minls config profiles			Lists the configured profiles, the active one is marked with *",
+	)
+	fmt.Println(
+		"	minls config set-secret <key>		Stores a secret (e.g. minio.access_secret) of the profile in the keyring [--delete]",
 	)
 	fmt.Println(
 		"	minls clear <option>			Clears program data (options: all / data / logs / downloads)",
 	)
+	fmt.Println()
+	fmt.Println("Global flags:")
+	fmt.Println(
+		"	--data-dir <dir>	History location (env MINLS_DATA_DIR, default $XDG_DATA_HOME/minls)",
+	)
+	fmt.Println(
+		"	--log-dir <dir>		Logs location (env MINLS_LOG_DIR, default $XDG_STATE_HOME/minls/logs)",
+	)
+	fmt.Println(
+		"	--config-dir <dir>	Config / .env location (env MINLS_CONFIG_DIR, default $XDG_CONFIG_HOME/minls)",
+	)
+	fmt.Println(
+		"	--set <key>=<value>	Overrides a config key (e.g. --set log.level=debug)",
+	)
+	fmt.Println(
+		"	--profile <name>	Config profile to use (env MINLS_PROFILE, default profiles.default or default)",
+	)
+	fmt.Println()
+	fmt.Println(
+		"Config precedence: flags > env > [profiles.<name>] > .minls.toml > .env > config.toml > secrets > defaults",
+	)
+	fmt.Println(
+		"Secrets are kept in the OS keyring, else in an encrypted secrets.enc in the config dir",
+	)
+	fmt.Println(
+		"(env MINLS_SECRETS_BACKEND auto / keyring / file / none, MINLS_SECRETS_PASSPHRASE for the file)",
+	)
 }
 
 func printHeader() {